@@ -1,4 +1,4 @@
-//go:generate bash -c "rm -f CH01_SEC02*.jpeg CH01_SEC02*.png"
+//go:generate bash -c "rm -f CH01_SEC02_[0-9][0-9]*.jpeg CH01_SEC02_[0-9][0-9]*.png"
 //go:generate gd -o CH01_SEC02.md CH01_SEC02.go
 
 package main
@@ -9,7 +9,6 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	"log"
-	"math"
 	"os"
 	"path/filepath"
 
@@ -17,6 +16,9 @@ import (
 
 	"github.com/kortschak/gd/show"
 
+	"github.com/kortschak/databook_gonum/imgmat"
+	"github.com/kortschak/databook_gonum/lowrank"
+
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
@@ -39,14 +41,8 @@ func main() {
 
 	show.JPEG(scaled(img, 600), nil, "", "Original image")
 
-	rect := img.Bounds()
-	rows, cols := rect.Dy(), rect.Dx()
-	a := mat.NewDense(rows, cols, nil)
-	for i := 0; i < rows; i++ {
-		for j := 0; j < cols; j++ {
-			a.Set(i, j, float64(color.GrayModel.Convert(img.At(rect.Min.Y+j, rect.Min.X+i)).(color.Gray).Y))
-		}
-	}
+	a := imgmat.Gray(img)
+	rows, cols := a.Dims()
 
 	fmt.Println(mat.Formatted(a, mat.Excerpt(4)))
 
@@ -60,19 +56,27 @@ func main() {
 	svd.VTo(&v)
 	sigma := svd.Values(nil)
 
+	/*{md}
+	In addition to the hand-picked ranks, the optimal hard threshold of
+	Gavish and Donoho gives a truncation rank for the case where the noise
+	level in the image is unknown.
+	*/
+	tau := lowrank.OptimalThreshold(sigma, rows, cols, 0)
+	rOpt := lowrank.OptimalRank(sigma, rows, cols, 0)
+	fmt.Printf("optimal hard threshold = %.4g (r = %d)\n", tau, rOpt)
+
+	ranks := []int{5, 20, 100}
+	if rOpt > 0 {
+		// Noise-dominated data may have no singular
+		// values above the threshold.
+		ranks = append(ranks, rOpt)
+	}
 	var aApprox mat.Dense
-	for _, r := range []int{5, 20, 100} {
+	for _, r := range ranks {
 		s := mat.NewDiagDense(r, sigma[:r])
 		aApprox.Product(u.Slice(0, rows, 0, r), s, v.Slice(0, cols, 0, r).T())
 
-		img := image.NewGray(rect)
-		for i := 0; i < rows; i++ {
-			for j := 0; j < cols; j++ {
-				v := math.Min(math.Max(0, aApprox.At(i, j)), 255)
-				img.Set(j, i, color.Gray{Y: uint8(v)})
-			}
-		}
-
+		img := imgmat.GrayImage(&aApprox, imgmat.Clamp)
 		show.JPEG(scaled(img, 600), nil, "", fmt.Sprintf("r = %d", r))
 	}
 
@@ -84,7 +88,22 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	p1.Add(values)
+	threshold := plotter.NewFunction(func(float64) float64 { return tau })
+	threshold.LineStyle.Color = color.RGBA{R: 255, A: 255}
+	threshold.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	p1.Add(values, threshold)
+	p1.Legend.Top = true
+	p1.Legend.Add("Optimal threshold", threshold)
+	if rOpt > 0 {
+		cutoff, err := plotter.NewScatter(plotter.XYs{{X: float64(rOpt - 1), Y: sigma[rOpt-1]}})
+		if err != nil {
+			log.Fatal(err)
+		}
+		cutoff.GlyphStyle.Color = color.RGBA{R: 255, A: 255}
+		cutoff.GlyphStyle.Shape = draw.CircleGlyph{}
+		p1.Add(cutoff)
+		p1.Legend.Add(fmt.Sprintf("r = %d", rOpt), cutoff)
+	}
 	c1 := vgimg.New(12*vg.Centimeter, 8*vg.Centimeter)
 	p1.Draw(draw.New(c1))
 	show.PNG(c1.Image(), "", "")
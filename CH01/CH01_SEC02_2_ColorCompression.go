@@ -0,0 +1,92 @@
+//go:generate bash -c "rm -f CH01_SEC02_2_ColorCompression*.jpeg CH01_SEC02_2_ColorCompression*.png"
+//go:generate gd -o CH01_SEC02_2_ColorCompression.md CH01_SEC02_2_ColorCompression.go
+
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+
+	drawimg "golang.org/x/image/draw"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/imgmat"
+	"github.com/kortschak/databook_gonum/lowrank"
+)
+
+func main() {
+	/*{md}
+	Each color image is split into its red, green and blue channels. The
+	channels are compressed either by truncating the SVD of each channel
+	independently, or by truncating the SVD of the channels stacked into a
+	single matrix so that they share a common set of right singular vectors.
+	The images are reduced to 600 pixels on their long edge before
+	factorization to keep the run time of the notebook reasonable.
+	*/
+	for _, name := range []string{"jupiter.jpg", "jelly.jpg"} {
+		img := scaled(decode(filepath.FromSlash("../DATA/"+name)), 600)
+		show.JPEG(img, nil, "", "Original image")
+
+		channels := imgmat.RGB(img)
+		for _, mode := range []struct {
+			name string
+			mode lowrank.ChannelMode
+		}{
+			{name: "per channel", mode: lowrank.PerChannel},
+			{name: "stacked", mode: lowrank.Stacked},
+		} {
+			c, err := lowrank.NewColor(channels, mode.mode)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, r := range []int{5, 20, 100} {
+				approx := c.Approx(r)
+				img, err := imgmat.RGBImage(approx[0], approx[1], approx[2], imgmat.Clamp)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Printf("%s %s r = %d: compression ratio = %.1f relative error = %.4f\n",
+					name, mode.name, r, c.CompressionRatio(r), c.RelativeError(r))
+				show.JPEG(img, nil, "", fmt.Sprintf("%s r = %d", mode.name, r))
+			}
+		}
+	}
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+func decode(path string) image.Image {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return img
+}
+
+func scaled(img image.Image, max int) image.Image {
+	rect := img.Bounds()
+	dx, dy := rect.Dx(), rect.Dy()
+	switch {
+	case dx < dy:
+		dx, dy = dx*max/dy, max
+	case dy < dx:
+		dx, dy = max, dy*max/dx
+	default:
+		dx, dy = max, max
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, dx, dy))
+	drawimg.NearestNeighbor.Scale(scaled, scaled.Bounds(), img, rect, drawimg.Over, nil)
+	return scaled
+}
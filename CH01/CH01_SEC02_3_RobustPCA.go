@@ -0,0 +1,112 @@
+//go:generate bash -c "rm -f CH01_SEC02_3_RobustPCA*.jpeg"
+//go:generate gd -o CH01_SEC02_3_RobustPCA.md CH01_SEC02_3_RobustPCA.go
+
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+
+	drawimg "golang.org/x/image/draw"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/imgmat"
+	"github.com/kortschak/databook_gonum/lowrank"
+	"github.com/kortschak/databook_gonum/seed"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func main() {
+	f, err := os.Open(filepath.FromSlash("../DATA/dog.jpg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	/*{md}
+	The image is reduced to 400 pixels on its long edge since robust PCA
+	computes a singular value decomposition at each iteration.
+	*/
+	a := imgmat.Gray(scaled(img, 400))
+	rows, cols := a.Dims()
+	show.JPEG(imgmat.GrayImage(a, imgmat.Clamp), nil, "", "Original image")
+
+	/*{md}
+	Ten percent of the pixels are corrupted with salt-and-pepper noise,
+	set to either black or white.
+	*/
+	rnd, err := seed.Rand()
+	if err != nil {
+		log.Fatal(err)
+	}
+	var x mat.Dense
+	x.CloneFrom(a)
+	for k := 0; k < rows*cols/10; k++ {
+		x.Set(rnd.Intn(rows), rnd.Intn(cols), float64(255*rnd.Intn(2)))
+	}
+	show.JPEG(imgmat.GrayImage(&x, imgmat.Clamp), nil, "", "Corrupted image")
+	fmt.Printf("corrupted: relative error = %.4f\n", relativeError(&x, a))
+
+	/*{md}
+	A truncated SVD at the optimal hard threshold spreads the gross
+	corruption across all of the retained modes.
+	*/
+	svd, err := lowrank.Factorize(&x)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r := lowrank.OptimalRank(svd.Sigma, rows, cols, 0)
+	var approx mat.Dense
+	svd.Approx(&approx, r)
+	show.JPEG(imgmat.GrayImage(&approx, imgmat.Clamp), nil, "", fmt.Sprintf("SVD r = %d", r))
+	fmt.Printf("SVD r = %d: relative error = %.4f\n", r, relativeError(&approx, a))
+
+	/*{md}
+	Robust PCA separates the corrupted image into a low-rank component and
+	a sparse component that captures the corrupted pixels.
+	*/
+	rpca, err := lowrank.Robust(&x, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("robust PCA: %d iterations, rank = %d\n", rpca.Iterations, rpca.Rank)
+	show.JPEG(imgmat.GrayImage(rpca.L, imgmat.Clamp), nil, "", "Low-rank component")
+	show.JPEG(imgmat.GrayImage(rpca.S, imgmat.Normalize), nil, "", "Sparse component")
+	fmt.Printf("robust PCA: relative error = %.4f\n", relativeError(rpca.L, a))
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+func relativeError(got, want mat.Matrix) float64 {
+	var d mat.Dense
+	d.Sub(got, want)
+	return mat.Norm(&d, 2) / mat.Norm(want, 2)
+}
+
+func scaled(img image.Image, max int) image.Image {
+	rect := img.Bounds()
+	dx, dy := rect.Dx(), rect.Dy()
+	switch {
+	case dx < dy:
+		dx, dy = dx*max/dy, max
+	case dy < dx:
+		dx, dy = max, dy*max/dx
+	default:
+		dx, dy = max, max
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, dx, dy))
+	drawimg.NearestNeighbor.Scale(scaled, scaled.Bounds(), img, rect, drawimg.Over, nil)
+	return scaled
+}
@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"image/color"
 	"log"
-	"math/rand"
+	"math"
 
 	"github.com/kortschak/gd/show"
 
+	"github.com/kortschak/databook_gonum/regress"
+	"github.com/kortschak/databook_gonum/seed"
+
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
 	"gonum.org/v1/plot"
@@ -21,6 +25,11 @@ import (
 )
 
 func main() {
+	rnd, err := seed.Rand()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	x := 3.0
 	a := mat.NewVecDense(16, nil)
 	for i := 0; i < a.Len(); i++ {
@@ -31,7 +40,7 @@ func main() {
 	b.ScaleVec(x, a)
 
 	for i := 0; i < a.Len(); i++ {
-		b.SetVec(i, b.AtVec(i)+rand.NormFloat64())
+		b.SetVec(i, b.AtVec(i)+rnd.NormFloat64())
 	}
 
 	var svd mat.SVD
@@ -46,7 +55,7 @@ func main() {
 	s := mat.NewDiagDense(len(sigma), sigma)
 
 	var sInv mat.Dense
-	err := sInv.Inverse(s)
+	err = sInv.Inverse(s)
 	if err != nil {
 		log.Fatalf("S is not invertible: %v", err)
 	}
@@ -101,13 +110,170 @@ func main() {
 	/*{md}
 	The second method shown for the Matlab and Python code is functionally identical to
 	the first method shown and is not directly provided by Gonum.
+
+	## Errors in variables
+
+	When a is also measured with error, the ordinary least squares estimate
+	is biased towards zero. Total least squares allows for errors of equal
+	variance in both a and b.
+	*/
+	const n = 200
+	aTrue := floats.Span(make([]float64, n), -2, 2)
+	aObs := mat.NewVecDense(n, nil)
+	bObs := mat.NewVecDense(n, nil)
+	for i, v := range aTrue {
+		aObs.SetVec(i, v+0.5*rnd.NormFloat64())
+		bObs.SetVec(i, x*v+0.5*rnd.NormFloat64())
+	}
+	ols, err := regress.Lstsq(aObs, bObs, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tls, err := regress.TLS(aObs, bObs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("OLS: %.4f\nTLS: %.4f\n", ols.X.AtVec(0), tls.AtVec(0))
+
+	p2 := plot.New()
+	p2.X.Label.Text = "a"
+	p2.Y.Label.Text = "b"
+	values, err = plotter.NewScatter(slicesToXYs(aObs.RawVector().Data, bObs.RawVector().Data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	values.GlyphStyle.Color = color.Gray{Y: 128}
+	values.GlyphStyle.Radius = 2
+	truth = line(x, color.Black, nil)
+	olsLine := line(ols.X.AtVec(0), color.RGBA{B: 255, A: 255}, []vg.Length{12, 4})
+	tlsLine := line(tls.AtVec(0), color.RGBA{R: 255, A: 255}, []vg.Length{4, 4})
+	p2.Add(values, truth, olsLine, tlsLine)
+	p2.Legend.Top = true
+	p2.Legend.Left = true
+	p2.Legend.Add("True line", truth)
+	p2.Legend.Add("Noisy data", values)
+	p2.Legend.Add("OLS", olsLine)
+	p2.Legend.Add("TLS", tlsLine)
+	c2 := vgimg.New(12*vg.Centimeter, 12*vg.Centimeter)
+	p2.Draw(draw.New(c2))
+	show.PNG(c2.Image(), "", "")
+
+	/*{md}
+	## Non-uniform variance
+
+	When the noise in b varies between measurements, weighting each
+	measurement by its inverse variance gives a more precise estimate.
+	Here alternate measurements are made by a precise and an imprecise
+	instrument.
+	Note that the ordinary least squares standard error assumes a uniform
+	variance and so is not reliable here.
 	*/
+	aVec := mat.NewVecDense(n, aTrue)
+	noise := make([]float64, n)
+	w := make([]float64, n)
+	for i, v := range aTrue {
+		noise[i] = 0.25
+		if i%2 == 1 {
+			noise[i] = 3
+		}
+		w[i] = 1 / (noise[i] * noise[i])
+		bObs.SetVec(i, x*v+noise[i]*rnd.NormFloat64())
+	}
+	ols, err = regress.Lstsq(aVec, bObs, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	wls, err := regress.WLS(aVec, bObs, w, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("OLS: %.4f ± %.4f\nWLS: %.4f ± %.4f\n", ols.X.AtVec(0), ols.StdErr[0], wls.X.AtVec(0), wls.StdErr[0])
+
+	p3 := plot.New()
+	p3.X.Label.Text = "a"
+	p3.Y.Label.Text = "b"
+	errs := make(plotter.YErrors, n)
+	for i, s := range noise {
+		errs[i].Low, errs[i].High = -s, s
+	}
+	bars, err := plotter.NewYErrorBars(struct {
+		plotter.XYs
+		plotter.YErrors
+	}{slicesToXYs(aTrue, bObs.RawVector().Data), errs})
+	if err != nil {
+		log.Fatal(err)
+	}
+	bars.LineStyle.Color = color.Gray{Y: 192}
+	values, err = plotter.NewScatter(slicesToXYs(aTrue, bObs.RawVector().Data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	values.GlyphStyle.Color = color.Gray{Y: 128}
+	values.GlyphStyle.Radius = 2
+	olsLine = line(ols.X.AtVec(0), color.RGBA{B: 255, A: 255}, []vg.Length{12, 4})
+	wlsLine := line(wls.X.AtVec(0), color.RGBA{R: 255, A: 255}, []vg.Length{4, 4})
+	p3.Add(bars, values, truth, olsLine, wlsLine)
+	p3.Legend.Top = true
+	p3.Legend.Left = true
+	p3.Legend.Add("True line", truth)
+	p3.Legend.Add("Noisy data", values)
+	p3.Legend.Add("OLS", olsLine)
+	p3.Legend.Add("WLS", wlsLine)
+	c3 := vgimg.New(12*vg.Centimeter, 12*vg.Centimeter)
+	p3.Draw(draw.New(c3))
+	show.PNG(c3.Image(), "", "")
+
+	/*{md}
+	## Correlated errors
+
+	When the errors in b are correlated, for example when the measurements
+	are made in sequence by an instrument that drifts, generalized least
+	squares uses the full error covariance. Here the errors follow a first
+	order autoregressive process with correlation φ between neighbouring
+	measurements, so the covariance is σ²φ^|i-j|. With a diagonal
+	covariance GLS is the same as WLS.
+	Correlated errors carry less information than independent errors, and
+	the ordinary least squares standard error does not account for this.
+	*/
+	const phi = 0.9
+	cov := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			cov.SetSym(i, j, math.Pow(phi, float64(j-i)))
+		}
+	}
+	e := rnd.NormFloat64()
+	for i, v := range aTrue {
+		if i > 0 {
+			e = phi*e + math.Sqrt(1-phi*phi)*rnd.NormFloat64()
+		}
+		bObs.SetVec(i, x*v+e)
+	}
+	ols, err = regress.Lstsq(aVec, bObs, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gls, err := regress.GLS(aVec, bObs, cov, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("OLS: %.4f ± %.4f\nGLS: %.4f ± %.4f\n", ols.X.AtVec(0), ols.StdErr[0], gls.X.AtVec(0), gls.StdErr[0])
 }
 
 /*{md}
 The code below is helper code only.
 */
 
+func line(slope float64, col color.Color, dashes []vg.Length) *plotter.Function {
+	l := plotter.NewFunction(func(a float64) float64 { return a * slope })
+	l.XMin = -2
+	l.XMax = 2
+	l.LineStyle.Color = col
+	l.LineStyle.Width = 2
+	l.LineStyle.Dashes = dashes
+	return l
+}
+
 func slicesToXYs(x, y []float64) plotter.XYs {
 	if len(x) != len(y) {
 		log.Fatalf("mismatched data lengths %d != %d", len(x), len(y))
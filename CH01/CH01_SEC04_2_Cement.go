@@ -4,15 +4,18 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
 	"image/color"
 	"log"
-	"os"
 	"path/filepath"
-	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/dataset"
+	"github.com/kortschak/databook_gonum/regress"
+
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -23,35 +26,25 @@ import (
 
 func main() {
 	var a mat.Dense
-	readCSV(&a, filepath.FromSlash("../DATA/hald_ingredients.csv"))
+	err := dataset.ReadCSV(&a, filepath.FromSlash("../DATA/hald_ingredients.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	var b mat.VecDense
-	readCSV(&b, filepath.FromSlash("../DATA/hald_heat.csv"))
-
-	var svd mat.SVD
-	ok := svd.Factorize(&a, mat.SVDThin)
-	if !ok {
-		log.Fatal("failed to factorize matrix")
-	}
-	var u, v mat.Dense
-	svd.UTo(&u)
-	svd.VTo(&v)
-	sigma := svd.Values(nil)
-	s := mat.NewDiagDense(len(sigma), sigma)
-
-	var sInv mat.Dense
-	err := sInv.Inverse(s)
+	err = dataset.ReadCSV(&b, filepath.FromSlash("../DATA/hald_heat.csv"))
 	if err != nil {
-		log.Fatalf("S is not invertible: %v", err)
+		log.Fatal(err)
 	}
 
-	var x mat.Dense
-	x.Product(&v, &sInv, u.T(), &b)
-
-	fmt.Println(mat.Formatted(x.T()))
+	// Solve Ax=b by the pseudo-inverse, A⁺ = V Σ⁻¹ Uᵀ, discarding
+	// singular values below the default tolerance.
+	fit, err := regress.Lstsq(&a, &b, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	var ax mat.Dense
-	ax.Mul(&a, &x)
+	fmt.Println(mat.Formatted(fit.X.T()))
 
 	p := plot.New()
 
@@ -60,7 +53,7 @@ func main() {
 		log.Fatal(err)
 	}
 	p.Add(heat)
-	rLine, rPoint, err := plotter.NewLinePoints(sliceToXYs(ax.RawMatrix().Data))
+	rLine, rPoint, err := plotter.NewLinePoints(sliceToXYs(fit.Fitted.RawVector().Data))
 	rLine.Color = color.RGBA{R: 255, A: 255}
 	rPoint.Color = color.RGBA{R: 255, A: 255}
 	if err != nil {
@@ -77,61 +70,18 @@ func main() {
 	p.Draw(draw.New(c))
 	show.PNG(c.Image(), "", "")
 
-	// # Alternative Methods:
-	//
-	// The first Matlab alternative, x = regress(b,A), is equivalent to a
-	// vector solve.
-	var x1 mat.VecDense
-	x1.SolveVec(&a, &b)
-	fmt.Println(mat.Formatted(x1.T()))
 	/*{md}
-	The second method shown for the Matlab and Python code is functionally identical to
-	the first method shown and is not directly provided by Gonum.
+	The Matlab regress function also reports confidence intervals for the
+	coefficients and statistics for the fit. These are available from the
+	least squares fit.
 	*/
+	printFit(fit, 0.95)
 }
 
 /*{md}
 The code below is helper code only.
 */
 
-func readCSV(dst mat.Matrix, path string) {
-	f, err := os.Open(path)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer f.Close()
-	r := csv.NewReader(f)
-	data, err := r.ReadAll()
-	if err != nil {
-		log.Fatal(err)
-	}
-	if len(data) == 0 {
-		log.Fatalf("%s is empty", path)
-	}
-	switch dst := dst.(type) {
-	case *mat.Dense:
-		dst.ReuseAs(len(data), len(data[0]))
-		for i, l := range data {
-			for j, s := range l {
-				v, err := strconv.ParseFloat(s, 64)
-				if err != nil {
-					log.Fatal(err)
-				}
-				dst.Set(i, j, v)
-			}
-		}
-	case *mat.VecDense:
-		dst.ReuseAsVec(len(data))
-		for i, l := range data {
-			v, err := strconv.ParseFloat(l[0], 64)
-			if err != nil {
-				log.Fatal(err)
-			}
-			dst.SetVec(i, v)
-		}
-	}
-}
-
 func sliceToXYs(s []float64) plotter.XYs {
 	xy := make(plotter.XYs, len(s))
 	for i, v := range s {
@@ -139,3 +89,18 @@ func sliceToXYs(s []float64) plotter.XYs {
 	}
 	return xy
 }
+
+func printFit(fit *regress.Fit, level float64) {
+	lower, upper := fit.ConfInt(level)
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\tcoef\tstd err\tt\tp\t%g%% lower\tupper\t\n", 100*level)
+	for j := 0; j < fit.X.Len(); j++ {
+		fmt.Fprintf(w, "x%d\t%.4f\t%.4f\t%.3f\t%.4f\t%.4f\t%.4f\t\n",
+			j+1, fit.X.AtVec(j), fit.StdErr[j], fit.T[j], fit.P[j], lower[j], upper[j])
+	}
+	w.Flush()
+	fmt.Print(buf.String())
+	fmt.Printf("rank = %d R² = %.4f residual variance = %.4f (%d degrees of freedom)\n",
+		fit.Rank, fit.R2, fit.Variance, fit.DoF)
+}
@@ -4,23 +4,23 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
-	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
-	"strings"
 
 	"github.com/kortschak/gd/show"
 
+	"github.com/kortschak/databook_gonum/dataset"
+	"github.com/kortschak/databook_gonum/preprocess"
+	"github.com/kortschak/databook_gonum/regress"
+
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/stat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 	"gonum.org/v1/plot/vg/vgimg"
@@ -28,26 +28,30 @@ import (
 
 func main() {
 	var h mat.Dense
-	readText(&h, filepath.FromSlash("../DATA/housing.data"))
-	r, c := h.Dims()
-	var a mat.Dense
-	a.CloneFrom(&h)
-	for i := 0; i < r; i++ {
-		a.Set(i, c-1, 1) // Pad with ones for nonzero offset
+	err := dataset.ReadText(&h, filepath.FromSlash("../DATA/housing.data"))
+	if err != nil {
+		log.Fatal(err)
 	}
+	r, c := h.Dims()
+	attr := h.Slice(0, r, 0, c-1)
 	b := h.ColView(c - 1)
 
-	// The Python code uses SVD to solve for Ax=b and the book uses
-	// Matlab's regress. Use SolveVec for simplicity.
-	var x mat.VecDense
-	x.SolveVec(&a, b)
+	var a mat.Dense
+	preprocess.Intercept{}.Transform(&a, attr) // Pad with ones for nonzero offset
 
-	var ax mat.VecDense
-	ax.MulVec(&a, &x)
+	// The Python code uses SVD to solve for Ax=b and the book uses
+	// Matlab's regress. Both are provided by regress.Lstsq.
+	fit, err := regress.Lstsq(&a, b, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("rank = %d R² = %.4f residual variance = %.4f (%d degrees of freedom)\n",
+		fit.Rank, fit.R2, fit.Variance, fit.DoF)
+	ax := fit.Fitted
 
 	p1 := []*plot.Plot{plot.New(), plot.New()}
 	value := line(vectorToSlice(b), color.RGBA{A: 255})
-	rLine, rPoint := linePoints(vectorToSlice(&ax), color.RGBA{R: 255, A: 255})
+	rLine, rPoint := linePoints(vectorToSlice(ax), color.RGBA{R: 255, A: 255})
 	p1[0].Add(value, rLine, rPoint)
 	p1[0].Legend.Top = false
 	p1[0].Legend.Left = true
@@ -56,7 +60,7 @@ func main() {
 
 	as := argSort{
 		arg:   vectorToSlice(b),
-		other: vectorToSlice(&ax),
+		other: vectorToSlice(ax),
 	}
 	sort.Sort(as)
 	sorted := line(as.arg, color.RGBA{A: 255})
@@ -76,30 +80,37 @@ func main() {
 
 	show.PNG(img.Image(), "", "")
 
-	aMean := mat.NewVecDense(c, nil)
-	for i := range aMean.RawVector().Data {
-		aMean.RawVector().Data[i] = stat.Mean(vectorToSlice(a.ColView(i)), nil)
+	// Standardize the attributes so that the magnitudes of the
+	// coefficients can be compared.
+	var z preprocess.ZScore
+	err = z.Fit(attr)
+	if err != nil {
+		log.Fatal(err)
 	}
-
 	var a2 mat.Dense
-	a2.Mul(onesVec(r), aMean.T())
-	a2.Sub(&a, &a2)
+	z.Transform(&a2, attr)
+	preprocess.Intercept{}.Transform(&a2, &a2)
 
-	for j := range aMean.RawVector().Data {
-		cj := a2.ColView(j).(*mat.VecDense)
-		a2Std := stat.StdDev(vectorToSlice(cj), nil)
-		cj.ScaleVec(1/a2Std, cj)
+	fit2, err := regress.Lstsq(&a2, b, regress.DefaultTol)
+	if err != nil {
+		log.Fatal(err)
 	}
-	a2.ColView(aMean.Len() - 1).(*mat.VecDense).CopyVec(onesVec(r))
 
-	x.Reset()
-	x.SolveVec(&a2, b)
+	/*{md}
+	The coefficients of the standardized fit can be returned to the original
+	units of the attributes, recovering the coefficients of the first fit.
+	*/
+	beta := vectorToSlice(fit2.X)
+	orig, intercept := z.Coefficients(beta[:c-1], beta[c-1])
+	fmt.Printf("%.4f %.4f\n", orig, intercept)
+	beta = vectorToSlice(fit.X)
+	fmt.Printf("%.4f %.4f\n", beta[:c-1], beta[c-1])
 
 	p2 := plot.New()
 	p2.X.Label.Text = "Attribute"
 	p2.Y.Label.Text = "Significance"
 	p2.X.Tick.Marker = plot.TickerFunc(integerTicks)
-	bar, err := plotter.NewBarChart(plotter.Values(vectorToSlice(&x)[:c-1]), 10)
+	bar, err := plotter.NewBarChart(plotter.Values(vectorToSlice(fit2.X)[:c-1]), 10)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -109,50 +120,67 @@ func main() {
 	c2 := vgimg.New(12*vg.Centimeter, 8*vg.Centimeter)
 	p2.Draw(draw.New(c2))
 	show.PNG(c2.Image(), "", "")
-}
 
-/*{md}
-The code below is helper code only.
-*/
+	/*{md}
+	Correlated attributes make the significance ranking of the least
+	squares coefficients unstable. The regularization paths of the LASSO,
+	the elastic net and ridge regression show how the coefficients of the
+	standardized attributes grow as the penalty is relaxed. The elastic net
+	mixes the LASSO and ridge penalties equally, so correlated attributes
+	enter the model together rather than one at a time. The penalized fits
+	do not include an intercept, so the housing values are centered instead.
+	*/
+	var std mat.Dense
+	z.Transform(&std, attr)
+	var center preprocess.Center
+	err = center.Fit(b)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var bc mat.Dense
+	center.Transform(&bc, b)
+	yc := bc.ColView(0)
 
-func readText(dst mat.Matrix, path string) {
-	f, err := os.Open(path)
+	lasso, err := regress.Lasso(&std, yc, regress.LambdaGrid(regress.LambdaMax(&std, yc, 1), 1e-3, 100))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer f.Close()
-	var data [][]string
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		data = append(data, strings.Fields(sc.Text()))
-	}
-	if len(data) == 0 {
-		log.Fatalf("%s is empty", path)
-	}
-	switch dst := dst.(type) {
-	case *mat.Dense:
-		dst.ReuseAs(len(data), len(data[0]))
-		for i, l := range data {
-			for j, s := range l {
-				v, err := strconv.ParseFloat(s, 64)
-				if err != nil {
-					log.Fatal(err)
-				}
-				dst.Set(i, j, v)
-			}
-		}
-	case *mat.VecDense:
-		dst.ReuseAsVec(len(data))
-		for i, l := range data {
-			v, err := strconv.ParseFloat(l[0], 64)
-			if err != nil {
-				log.Fatal(err)
-			}
-			dst.SetVec(i, v)
+	const alpha = 0.5
+	enet, err := regress.ElasticNet(&std, yc, alpha, regress.LambdaGrid(regress.LambdaMax(&std, yc, alpha), 1e-3, 100))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ridge, err := regress.Ridge(&std, yc, regress.LambdaGrid(1e4, 1e-6, 100))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p3 := [][]*plot.Plot{{pathPlot("LASSO", lasso), pathPlot("Elastic net (α=0.5)", enet), pathPlot("Ridge", ridge)}}
+	legend := plot.New()
+	legend.HideAxes()
+	legend.Legend.Top = true
+	for j := 0; j < c-1; j++ {
+		l, err := plotter.NewLine(plotter.XYs{})
+		if err != nil {
+			log.Fatal(err)
 		}
+		l.LineStyle = pathStyle(j)
+		legend.Legend.Add(fmt.Sprintf("Attribute %d", j+1), l)
+	}
+	c3 := vgimg.New(28*vg.Centimeter, 10*vg.Centimeter)
+	dc := draw.New(c3)
+	legendWidth := 3 * vg.Centimeter
+	canvases = plot.Align(p3, draw.Tiles{Rows: 1, Cols: 3}, draw.Crop(dc, 0, -legendWidth, 0, 0))
+	for i, c := range canvases[0] {
+		p3[0][i].Draw(c)
 	}
+	legend.Draw(draw.Crop(dc, dc.Size().X-legendWidth, 0, 0, 0))
+	show.PNG(c3.Image(), "", "")
 }
 
+/*{md}
+The code below is helper code only.
+*/
+
 func line(s []float64, col color.Color) *plotter.Line {
 	l, err := plotter.NewLine(sliceToXYs(s))
 	if err != nil {
@@ -198,13 +226,44 @@ func (a argSort) Swap(i, j int) {
 	a.other[i], a.other[j] = a.other[j], a.other[i]
 }
 
-type onesVec int
+// pathPlot returns a plot of the coefficients of a regularization path
+// against the regularization parameter.
+func pathPlot(title string, path *regress.Path) *plot.Plot {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "λ"
+	p.Y.Label.Text = "Coefficient"
+	p.X.Scale = plot.LogScale{}
+	p.X.Tick.Marker = plot.LogTicks{}
+	rows, _ := path.Coef.Dims()
+	for j := 0; j < rows; j++ {
+		l, err := plotter.NewLine(slicesToXYs(path.Lambda, path.Coef.RawRowView(j)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		l.LineStyle = pathStyle(j)
+		p.Add(l)
+	}
+	return p
+}
 
-func (v onesVec) At(i, j int) float64 { return 1 }
-func (v onesVec) AtVec(i int) float64 { return 1 }
-func (v onesVec) Dims() (r, c int)    { return int(v), 1 }
-func (v onesVec) Len() int            { return int(v) }
-func (v onesVec) T() mat.Matrix       { return mat.Transpose{v} }
+// pathStyle returns a distinct line style for the jth coefficient path,
+// cycling through dash patterns when the colors are exhausted.
+func pathStyle(j int) draw.LineStyle {
+	return draw.LineStyle{
+		Color:  plotutil.Color(j),
+		Width:  vg.Points(1),
+		Dashes: plotutil.Dashes(j / len(plotutil.DefaultColors)),
+	}
+}
+
+func slicesToXYs(x, y []float64) plotter.XYs {
+	xy := make(plotter.XYs, len(x))
+	for i := range x {
+		xy[i] = plotter.XY{X: x[i], Y: y[i]}
+	}
+	return xy
+}
 
 func integerTicks(min, max float64) []plot.Tick {
 	var ticks []plot.Tick
@@ -0,0 +1,217 @@
+//go:generate bash -c "rm -f CH01_SEC05_1_OvarianCancer*.png"
+//go:generate gd -o CH01_SEC05_1_OvarianCancer.md CH01_SEC05_1_OvarianCancer.go
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"path/filepath"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/dataset"
+	"github.com/kortschak/databook_gonum/pca"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func main() {
+	/*{md}
+	The ovarian cancer observations, ovariancancer_obs.csv, are not included
+	in the DATA directory of this repository and must be copied there from
+	the book's data archive. The file holds 216 rows of 4000 gene expression
+	measurements, one row per patient, corresponding to the class labels in
+	ovariancancer_grp.csv.
+	*/
+	obs, err := dataset.ReadLabelled(
+		filepath.FromSlash("../DATA/ovariancancer_obs.csv"), dataset.CSV,
+		filepath.FromSlash("../DATA/ovariancancer_grp.csv"), dataset.CSV,
+		false,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	/*{md}
+	As in the book, the observations are factorized without subtracting
+	the mean.
+	*/
+	p, err := pca.Fit(obs.X, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ratio := p.ExplainedRatio()
+	cumulative := p.Cumulative()
+	for i := 0; i < 3; i++ {
+		fmt.Printf("PC%d: explained = %.4f cumulative = %.4f\n", i+1, ratio[i], cumulative[i])
+	}
+
+	p1 := plot.New()
+	p1.Title.Text = "Singular Values"
+	p1.Y.Scale = plot.LogScale{}
+	p1.Y.Tick.Marker = plot.LogTicks{}
+	values, err := plotter.NewLine(sliceToXYs(p.Sigma))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p1.Add(values)
+	c1 := vgimg.New(12*vg.Centimeter, 8*vg.Centimeter)
+	p1.Draw(draw.New(c1))
+	show.PNG(c1.Image(), "", "")
+
+	p2 := plot.New()
+	p2.Title.Text = "Singular Values: Cumulative Sum"
+	cumsum := floats.CumSum(make([]float64, len(p.Sigma)), p.Sigma)
+	floats.Scale(1/floats.Max(cumsum), cumsum)
+	values, err = plotter.NewLine(sliceToXYs(cumsum))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p2.Add(values)
+	c2 := vgimg.New(12*vg.Centimeter, 8*vg.Centimeter)
+	p2.Draw(draw.New(c2))
+	show.PNG(c2.Image(), "", "")
+
+	/*{md}
+	Each patient is projected onto the first three principal directions
+	and the projections are shown from the book's viewpoint, an azimuth
+	of 85° and an elevation of 25°.
+	*/
+	var scores mat.Dense
+	p.Scores(&scores, obs.X, 3)
+
+	proj := newProjector(&scores, 85, 25)
+	p3 := plot.New()
+	p3.HideAxes()
+	p3.Add(proj.axes([3]string{"PC1", "PC2", "PC3"})...)
+	styles := map[string]struct {
+		color color.Color
+		shape draw.GlyphDrawer
+	}{
+		"Cancer": {color: color.RGBA{R: 255, A: 255}, shape: draw.CrossGlyph{}},
+		"Normal": {color: color.RGBA{B: 255, A: 255}, shape: draw.CircleGlyph{}},
+	}
+	for _, label := range obs.Dict.Labels() {
+		var xys plotter.XYs
+		for i, ok := range obs.Mask(label) {
+			if ok {
+				xys = append(xys, proj.project(scores.RawRowView(i)))
+			}
+		}
+		s, err := plotter.NewScatter(xys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		style, ok := styles[label]
+		if ok {
+			s.GlyphStyle.Color = style.color
+			s.GlyphStyle.Shape = style.shape
+		}
+		p3.Add(s)
+		p3.Legend.Add(label, s)
+	}
+	p3.Legend.Top = true
+	p3.Legend.Left = true
+	c3 := vgimg.New(15*vg.Centimeter, 12*vg.Centimeter)
+	p3.Draw(draw.New(c3))
+	show.PNG(c3.Image(), "", "")
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+func sliceToXYs(s []float64) plotter.XYs {
+	xy := make(plotter.XYs, len(s))
+	for i, v := range s {
+		xy[i] = plotter.XY{X: float64(i), Y: v}
+	}
+	return xy
+}
+
+// projector is an orthographic projection of 3D points onto the plane of a
+// view with an azimuth and elevation in degrees, following the MATLAB view
+// convention. Each coordinate is scaled to [-1, 1] over the range of the
+// points before projection so that the view fills a cube, as MATLAB's axes
+// do by default.
+type projector struct {
+	az, el   float64
+	min, max [3]float64
+}
+
+func newProjector(pts *mat.Dense, az, el float64) projector {
+	p := projector{az: az * math.Pi / 180, el: el * math.Pi / 180}
+	r, _ := pts.Dims()
+	for j := range p.min {
+		p.min[j], p.max[j] = math.Inf(1), math.Inf(-1)
+		for i := 0; i < r; i++ {
+			p.min[j] = math.Min(p.min[j], pts.At(i, j))
+			p.max[j] = math.Max(p.max[j], pts.At(i, j))
+		}
+	}
+	return p
+}
+
+func (p projector) project(pt []float64) plotter.XY {
+	var s [3]float64
+	for j := range s {
+		s[j] = p.scale(j, pt[j])
+	}
+	return p.view(s)
+}
+
+func (p projector) scale(j int, v float64) float64 {
+	if p.max[j] == p.min[j] {
+		return 0
+	}
+	return 2*(v-p.min[j])/(p.max[j]-p.min[j]) - 1
+}
+
+func (p projector) view(s [3]float64) plotter.XY {
+	sinAz, cosAz := math.Sincos(p.az)
+	sinEl, cosEl := math.Sincos(p.el)
+	return plotter.XY{
+		X: cosAz*s[0] + sinAz*s[1],
+		Y: -sinEl*sinAz*s[0] + sinEl*cosAz*s[1] + cosEl*s[2],
+	}
+}
+
+// axes returns plotters for the three axes of the view cube drawn from its
+// rear lower corner, labelled at their ends.
+func (p projector) axes(names [3]string) []plot.Plotter {
+	origin := [3]float64{-1, 1, -1}
+	if math.Sin(p.az) < 0 {
+		origin[0] = 1
+	}
+	if math.Cos(p.az) < 0 {
+		origin[1] = -1
+	}
+	var plotters []plot.Plotter
+	labels := plotter.XYLabels{}
+	for j, name := range names {
+		end := origin
+		end[j] = -origin[j]
+		l, err := plotter.NewLine(plotter.XYs{p.view(origin), p.view(end)})
+		if err != nil {
+			log.Fatal(err)
+		}
+		l.LineStyle.Color = color.Gray{Y: 128}
+		plotters = append(plotters, l)
+		labels.XYs = append(labels.XYs, p.view(end))
+		labels.Labels = append(labels.Labels, name)
+	}
+	l, err := plotter.NewLabels(labels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return append(plotters, l)
+}
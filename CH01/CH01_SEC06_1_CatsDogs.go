@@ -0,0 +1,165 @@
+//go:generate bash -c "rm -f CH01_SEC06_1_CatsDogs*.png"
+//go:generate gd -o CH01_SEC06_1_CatsDogs.md CH01_SEC06_1_CatsDogs.go
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"path/filepath"
+	"sort"
+
+	drawimg "golang.org/x/image/draw"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/imgmat"
+	"github.com/kortschak/databook_gonum/lowrank"
+	"github.com/kortschak/databook_gonum/matfile"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+const side = 64 // Images are 64×64 pixels stored as columns.
+
+func main() {
+	cat := load(filepath.FromSlash("../DATA/catData.mat"), "cat")
+	dog := load(filepath.FromSlash("../DATA/dogData.mat"), "dog")
+	rows, n := cat.Dims()
+
+	var x mat.Dense
+	x.Augment(cat, dog)
+	fmt.Printf("%d images of %d pixels\n", 2*n, rows)
+
+	show.PNG(grid(columns(&x, 0, 1, 2, 3, n, n+1, n+2, n+3), 4, imgmat.Clamp), "", "Sample cats and dogs")
+
+	/*{md}
+	The mean image is subtracted from each image before the singular
+	value decomposition so that the left singular vectors describe the
+	variation between the animals.
+	*/
+	mean := mat.NewVecDense(rows, nil)
+	for j := 0; j < 2*n; j++ {
+		mean.AddVec(mean, x.ColView(j))
+	}
+	mean.ScaleVec(1/float64(2*n), mean)
+	show.PNG(grid([]mat.Vector{mean}, 1, imgmat.Clamp), "", "Mean image")
+
+	for j := 0; j < 2*n; j++ {
+		col := x.ColView(j).(*mat.VecDense)
+		col.SubVec(col, mean)
+	}
+	svd, err := lowrank.Factorize(&x)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cumsum := floats.CumSum(make([]float64, len(svd.Sigma)), svd.Sigma)
+	floats.Scale(1/floats.Max(cumsum), cumsum)
+	fmt.Printf("first 8 modes hold %.1f%% of the singular value sum\n", 100*cumsum[7])
+
+	show.PNG(grid(columns(svd.U, 0, 1, 2, 3, 4, 5, 6, 7), 4, imgmat.Normalize), "", "Leading eigen-images")
+
+	/*{md}
+	Each animal is projected onto the leading modes. The separation of the
+	cats and dogs along each mode is measured by the Fisher ratio, the
+	squared difference in the mean projections divided by the sum of their
+	variances.
+	*/
+	var proj mat.Dense
+	proj.Mul(svd.U.Slice(0, rows, 0, 8).T(), &x)
+	type mode struct {
+		index  int
+		fisher float64
+	}
+	modes := make([]mode, 8)
+	for k := range modes {
+		row := proj.RawRowView(k)
+		cMean, cVar := stat.MeanVariance(row[:n], nil)
+		dMean, dVar := stat.MeanVariance(row[n:], nil)
+		modes[k] = mode{index: k, fisher: (cMean - dMean) * (cMean - dMean) / (cVar + dVar)}
+		fmt.Printf("mode %d: Fisher ratio = %.3f\n", k+1, modes[k].fisher)
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i].fisher > modes[j].fisher })
+	a, b := modes[0].index, modes[1].index
+
+	p := plot.New()
+	p.X.Label.Text = fmt.Sprintf("Mode %d", a+1)
+	p.Y.Label.Text = fmt.Sprintf("Mode %d", b+1)
+	for i, animal := range []struct {
+		name  string
+		color color.Color
+		shape draw.GlyphDrawer
+	}{
+		{name: "Cat", color: color.RGBA{R: 255, A: 255}, shape: draw.CrossGlyph{}},
+		{name: "Dog", color: color.RGBA{B: 255, A: 255}, shape: draw.CircleGlyph{}},
+	} {
+		xys := make(plotter.XYs, n)
+		for j := range xys {
+			xys[j] = plotter.XY{X: proj.At(a, i*n+j), Y: proj.At(b, i*n+j)}
+		}
+		s, err := plotter.NewScatter(xys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.GlyphStyle.Color = animal.color
+		s.GlyphStyle.Shape = animal.shape
+		p.Add(s)
+		p.Legend.Add(animal.name, s)
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+	c := vgimg.New(12*vg.Centimeter, 12*vg.Centimeter)
+	p.Draw(draw.New(c))
+	show.PNG(c.Image(), "", "")
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+func load(path, name string) *mat.Dense {
+	f, err := matfile.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m, err := f.Dense(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return m
+}
+
+func columns(m mat.ColViewer, idx ...int) []mat.Vector {
+	cols := make([]mat.Vector, len(idx))
+	for i, j := range idx {
+		cols[i] = m.ColView(j)
+	}
+	return cols
+}
+
+// grid returns the image columns in vecs tiled into a grid with the given
+// number of columns and magnified for display.
+func grid(vecs []mat.Vector, cols int, s imgmat.Scaling) image.Image {
+	imgs := make([]image.Image, len(vecs))
+	for i, v := range vecs {
+		imgs[i] = imgmat.GrayImage(imgmat.Reshape(v, side, side), s)
+	}
+	g := imgmat.Grid(imgs, cols, 2, color.White)
+	return magnified(g, 3)
+}
+
+func magnified(img image.Image, f int) image.Image {
+	rect := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, f*rect.Dx(), f*rect.Dy()))
+	drawimg.NearestNeighbor.Scale(dst, dst.Bounds(), img, rect, drawimg.Src, nil)
+	return dst
+}
@@ -4,16 +4,17 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
 	"log"
 	"math"
-	"math/cmplx"
-	"math/rand"
 
 	"github.com/kortschak/gd/show"
 
-	"gonum.org/v1/gonum/cmplxs"
-	"gonum.org/v1/gonum/dsp/fourier"
+	"github.com/kortschak/databook_gonum/seed"
+	"github.com/kortschak/databook_gonum/spectral"
+
+	"gonum.org/v1/gonum/dsp/window"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -28,36 +29,26 @@ func main() {
 	t := floats.Span(make([]float64, int(1/dt)), 0, 1-dt)
 	f := make([]float64, len(t))
 	fClean := make([]float64, len(t))
+	rnd, err := seed.Rand()
+	if err != nil {
+		log.Fatal(err)
+	}
 	for i, v := range t {
 		v = math.Sin(2*math.Pi*50*v) + math.Sin(2*math.Pi*120*v) // Sum of 2 frequencies
 		fClean[i] = v
-		f[i] = v + 2.5*rand.NormFloat64()
+		f[i] = v + 2.5*rnd.NormFloat64()
 	}
 
-	// Compute the Fast Fourier Transform (FFT)
-	n := len(t)
-	fft := fourier.NewFFT(n)
-	fHat := fft.Coefficients(nil, f)
-	psd := make([]float64, len(fHat))
-	for i, v := range fHat {
-		psd[i] = real(v*cmplx.Conj(v)) / float64(n)
-	}
-	freq := make([]float64, len(psd))
-	for i := range freq {
-		freq[i] = fft.Freq(i) * float64(n)
-	}
+	// Compute the power spectral density (PSD) in squared signal units per Hz
+	fs := 1 / dt
+	raw := spectral.Periodogram(f, fs, nil)
 
-	// Use the PSD to filter out noise
-	psdClean := make([]float64, len(psd))
-	for i, v := range psd {
-		if v > 100 {
-			psdClean[i] = v
-		} else {
-			fHat[i] = 0
-		}
-	}
-	cmplxs.Scale(complex(1/float64(n), 0), fHat)
-	fFilt := fft.Sequence(nil, fHat)
+	// Use the PSD to filter out noise. A threshold of 0.2/Hz is equivalent
+	// to the threshold of 100 applied to |f̂|²/n in the Python code.
+	threshold := spectral.PSDThreshold{Level: 0.2}
+	fFilt := spectral.Apply(nil, f, fs, threshold)
+	psdClean := threshold.Gains(make([]float64, len(raw.Power)), raw)
+	floats.Mul(psdClean, raw.Power)
 
 	// Plots
 	p := [][]*plot.Plot{{plot.New()}, {plot.New()}, {plot.New()}}
@@ -75,10 +66,10 @@ func main() {
 	p[1][0].Legend.Add("Clean", original)
 	p[1][0].Legend.Add("Filtered", filtered)
 
-	psdLine := line(freq, psd, color.RGBA{R: 255, A: 255})
-	filteredPsdLine := line(freq, psdClean, color.RGBA{B: 255, A: 255})
+	psdLine := line(raw.Freq, raw.Power, color.RGBA{R: 255, A: 255})
+	filteredPsdLine := line(raw.Freq, psdClean, color.RGBA{B: 255, A: 255})
 	p[2][0].Add(psdLine, filteredPsdLine)
-	p[2][0].X.Tick.Marker = plot.ConstantTicks{{100, "100"}, {200, "200"}, {300, "300"}, {400, "400"}}
+	p[2][0].X.Tick.Marker = plot.ConstantTicks{{Value: 100, Label: "100"}, {Value: 200, Label: "200"}, {Value: 300, Label: "300"}, {Value: 400, Label: "400"}}
 	p[2][0].Legend.Top = true
 	p[2][0].Legend.Add("Noisy", psdLine)
 	p[2][0].Legend.Add("Filtered", filteredPsdLine)
@@ -90,12 +81,137 @@ func main() {
 	}
 
 	show.PNG(img.Image(), "", "")
+
+	/*{md}
+	## Estimating the power spectral density
+
+	The threshold above was chosen by eye. Scaling the PSD to physical
+	units, squared signal units per Hz, and estimating its uncertainty makes
+	the choice principled. The periodogram has only two degrees of freedom
+	at each frequency, so its estimates are very noisy. Welch's method
+	averages the periodograms of overlapping windowed segments and the
+	multitaper method averages the periodograms obtained with orthogonal
+	Slepian tapers, both trading frequency resolution for lower variance.
+	*/
+	welch, err := spectral.Welch(f, fs, spectral.STFT{Length: 128, Window: window.Hann})
+	if err != nil {
+		log.Fatal(err)
+	}
+	mt, err := spectral.Multitaper(f, fs, 4, 7)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	/*{md}
+	A noise frequency is unlikely to exceed a threshold set at the upper
+	quantile of the chi-squared distribution of a white noise estimate.
+	The significance level is divided by the number of frequencies so that
+	it applies to the spectrum as a whole. The expected white noise level
+	is 2σ²/fs = 0.0125.
+	*/
+	const alpha = 0.05
+	p2 := plot.New()
+	p2.X.Label.Text = "frequency (Hz)"
+	p2.X.Tick.Marker = plot.ConstantTicks{
+		{Value: 0, Label: "0"}, {Value: 100, Label: "100"}, {Value: 200, Label: "200"},
+		{Value: 300, Label: "300"}, {Value: 400, Label: "400"}, {Value: 500, Label: "500"},
+	}
+	p2.Y.Label.Text = "PSD (1/Hz)"
+	p2.Y.Scale = plot.LogScale{}
+	p2.Y.Tick.Marker = plot.LogTicks{}
+	p2.Legend.Top = true
+	for _, est := range []struct {
+		name string
+		psd  *spectral.PSD
+		col  color.Color
+	}{
+		{name: "Periodogram", psd: raw, col: color.Gray{Y: 160}},
+		{name: "Welch", psd: welch, col: color.RGBA{B: 255, A: 255}},
+		{name: "Multitaper", psd: mt, col: color.RGBA{R: 255, A: 255}},
+	} {
+		thresh := est.psd.Threshold(alpha / float64(len(est.psd.Power)))
+		var peaks []float64
+		for i, v := range est.psd.Power {
+			if v > thresh {
+				peaks = append(peaks, est.psd.Freq[i])
+			}
+		}
+		fmt.Printf("%s: %.1f degrees of freedom, threshold = %.4g, peaks at %v Hz\n", est.name, est.psd.DoF, thresh, peaks)
+
+		l := line(est.psd.Freq, est.psd.Power, est.col)
+		p2.Add(l)
+		p2.Legend.Add(est.name, l)
+		if est.psd != raw {
+			lower, upper := est.psd.ConfInt(0.95)
+			for _, bound := range [][]float64{lower, upper} {
+				b := line(est.psd.Freq, bound, est.col)
+				b.LineStyle.Dashes = []vg.Length{2, 2}
+				p2.Add(b)
+			}
+		}
+	}
+	c2 := vgimg.New(18*vg.Centimeter, 12*vg.Centimeter)
+	p2.Draw(draw.New(c2))
+	show.PNG(c2.Image(), "", "")
+
+	/*{md}
+	## Comparing filters
+
+	The PSD threshold filter keeps only the frequencies that are unlikely to
+	be noise, with the threshold set from the periodogram as above. The
+	Wiener filter instead scales each frequency by the estimated fraction of
+	its power that is due to the signal, but since the periodogram has only
+	two degrees of freedom, many noise frequencies appear to carry signal
+	and are passed. Low-pass filters only use the knowledge that the signal
+	has no components above 150 Hz and so keep all the noise below that,
+	and a notch filter can additionally remove the band between the two
+	components. Since the signal is sparse in frequency, thresholding the
+	PSD is by far the most effective here.
+	*/
+	filters := []struct {
+		name   string
+		filter spectral.Filter
+	}{
+		{name: "PSD threshold", filter: spectral.PSDThreshold{Level: raw.Threshold(alpha / float64(len(raw.Power)))}},
+		{name: "Wiener", filter: spectral.Wiener{Noise: raw.NoiseLevel()}},
+		{name: "Ideal low-pass", filter: spectral.Lowpass{Cutoff: 150}},
+		{name: "Butterworth low-pass", filter: spectral.Lowpass{Cutoff: 150, Order: 8}},
+		{name: "Butterworth low-pass and notch", filter: spectral.Cascade{
+			spectral.Lowpass{Cutoff: 150, Order: 8},
+			spectral.Notch{Low: 65, High: 105, Order: 4},
+		}},
+	}
+	fmt.Printf("noisy: RMS error = %.4f\n", rmsError(f, fClean))
+	const shown = 200 // Show the first 0.2 seconds.
+	p3 := make([][]*plot.Plot, len(filters))
+	for i, filt := range filters {
+		out := spectral.Apply(nil, f, fs, filt.filter)
+		fmt.Printf("%s: RMS error = %.4f\n", filt.name, rmsError(out, fClean))
+
+		p := plot.New()
+		p.Title.Text = filt.name
+		clean := line(t[:shown], fClean[:shown], color.RGBA{A: 255})
+		filtered := line(t[:shown], out[:shown], color.RGBA{B: 255, A: 255})
+		p.Add(clean, filtered)
+		p.Y.Min, p.Y.Max = -2.5, 2.5
+		p3[i] = []*plot.Plot{p}
+	}
+	img3 := vgimg.New(18*vg.Centimeter, 25*vg.Centimeter)
+	canvases3 := plot.Align(p3, draw.Tiles{Rows: len(p3), Cols: 1, PadY: vg.Centimeter / 2}, draw.New(img3))
+	for i, c := range canvases3 {
+		p3[i][0].Draw(c[0])
+	}
+	show.PNG(img3.Image(), "", "")
 }
 
 /*{md}
 The code below is helper code only.
 */
 
+func rmsError(got, want []float64) float64 {
+	return floats.Distance(got, want, 2) / math.Sqrt(float64(len(want)))
+}
+
 func line(x, y []float64, col color.Color) *plotter.Line {
 	l, err := plotter.NewLine(slicesToXYs(x, y))
 	if err != nil {
@@ -0,0 +1,154 @@
+//go:generate bash -c "rm -f CH02_SEC05_2_Beethoven*.png"
+//go:generate gd -o CH02_SEC05_2_Beethoven.md CH02_SEC05_2_Beethoven.go
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/matfile"
+	"github.com/kortschak/databook_gonum/spectral"
+
+	"gonum.org/v1/gonum/dsp/window"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func main() {
+	f, err := matfile.Open(filepath.FromSlash("../DATA/beethoven_40sec.mat"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	y, err := f.Dense("y")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fsm, err := f.Dense("FS")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fs := fsm.At(0, 0)
+	sig := y.RawRowView(0)
+	fmt.Printf("%d samples at %v Hz (%.1f seconds)\n", len(sig), fs, float64(len(sig))/fs)
+
+	/*{md}
+	The spectrogram is computed from overlapping Hann-windowed segments of
+	4096 samples, about 0.17 seconds, zero padded to 8192 samples to give
+	a finer frequency grid. Only frequencies up to 1 kHz are shown.
+	*/
+	const maxFreq = 1000
+	stft := spectral.STFT{
+		Length: 4096,
+		Hop:    1024,
+		Pad:    8192,
+		Window: window.Hann,
+	}
+	p, err := spectrogram(stft, sig, fs, maxFreq)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.Title.Text = "Hann window"
+	c := vgimg.New(18*vg.Centimeter, 10*vg.Centimeter)
+	p.Draw(draw.New(c))
+	show.PNG(c.Image(), "", "")
+
+	/*{md}
+	A Gabor transform uses a Gaussian window, exp(-a t²). The width of the
+	window trades time resolution against frequency resolution. A narrow
+	window resolves the onsets of notes but blurs their pitch while a wide
+	window resolves the pitch but smears the notes in time. The first ten
+	seconds are shown for a narrow and a wide window.
+	*/
+	first := sig[:int(10*fs)]
+	var plots [][]*plot.Plot
+	for _, a := range []float64{1e4, 1e2} {
+		// Cover three standard deviations either side of the center.
+		sigma := 1 / math.Sqrt(2*a)
+		n := int(6 * sigma * fs)
+		pad := 1
+		for pad < 2*n {
+			pad <<= 1
+		}
+		stft := spectral.STFT{
+			Length: n,
+			Hop:    int(fs / 100),
+			Pad:    pad,
+			Window: spectral.Gabor(a, fs),
+		}
+		p, err := spectrogram(stft, first, fs, maxFreq)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p.Title.Text = fmt.Sprintf("Gabor window, a = %g", a)
+		plots = append(plots, []*plot.Plot{p})
+	}
+	img := vgimg.New(18*vg.Centimeter, 18*vg.Centimeter)
+	canvases := plot.Align(plots, draw.Tiles{Rows: 2, Cols: 1, PadY: vg.Centimeter}, draw.New(img))
+	for i, c := range canvases {
+		plots[i][0].Draw(c[0])
+	}
+	show.PNG(img.Image(), "", "")
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+// spectrogram returns a plot of the log power spectrogram of sig
+// with frequencies up to maxFreq. Powers more than 80 dB below the
+// maximum are shown as the minimum.
+func spectrogram(stft spectral.STFT, sig []float64, fs, maxFreq float64) (*plot.Plot, error) {
+	s, err := stft.Spectrogram(sig)
+	if err != nil {
+		return nil, err
+	}
+	freqs := stft.Freqs(nil, fs)
+	rows := len(freqs)
+	for i, f := range freqs {
+		if f > maxFreq {
+			rows = i
+			break
+		}
+	}
+	_, cols := s.Dims()
+	power := mat.DenseCopyOf(s.Slice(0, rows, 0, cols))
+	power.Apply(func(_, _ int, v float64) float64 { return 10 * math.Log10(v+1e-12) }, power)
+
+	pal := moreland.ExtendedBlackBody().Palette(256)
+	p := plot.New()
+	p.X.Label.Text = "time (s)"
+	p.Y.Label.Text = "frequency (Hz)"
+	h := plotter.NewHeatMap(grid{
+		data: power,
+		x:    stft.Times(nil, len(sig), fs),
+		y:    freqs[:rows],
+	}, pal)
+	h.Max = mat.Max(power)
+	h.Min = h.Max - 80
+	h.Underflow = pal.Colors()[0]
+	h.Rasterized = true
+	p.Add(h)
+	return p, nil
+}
+
+// grid is a plotter.GridXYZ with rows of the data
+// at y and columns at x.
+type grid struct {
+	data mat.Matrix
+	x, y []float64
+}
+
+func (g grid) Dims() (c, r int)   { return len(g.x), len(g.y) }
+func (g grid) Z(c, r int) float64 { return g.data.At(r, c) }
+func (g grid) X(c int) float64    { return g.x[c] }
+func (g grid) Y(r int) float64    { return g.y[r] }
@@ -0,0 +1,151 @@
+//go:generate bash -c "rm -f CH02_SEC06_1_2DFFT*.jpeg CH02_SEC06_1_2DFFT*.png"
+//go:generate gd -o CH02_SEC06_1_2DFFT.md CH02_SEC06_1_2DFFT.go
+
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"log"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"sort"
+
+	drawimg "golang.org/x/image/draw"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/imgmat"
+	"github.com/kortschak/databook_gonum/spectral"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func main() {
+	f, err := os.Open(filepath.FromSlash("../DATA/dog.jpg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	a := imgmat.Gray(img)
+	rows, cols := a.Dims()
+	show.JPEG(scaled(imgmat.GrayImage(a, imgmat.Clamp), 600), nil, "", "Original image")
+
+	/*{md}
+	The two-dimensional FFT is computed by transforming each row of the
+	image and then each column of the result. Since the image is real,
+	only the non-negative column frequencies are stored, the remainder
+	being given by conjugate symmetry.
+	*/
+	fft := spectral.NewFFT2(rows, cols)
+	bt := fft.Coefficients(nil, a)
+
+	mag := fft.Magnitude(nil, bt)
+	mag.Apply(func(_, _ int, v float64) float64 { return math.Log(v + 1) }, mag)
+
+	p := plot.New()
+	p.Title.Text = "log(|F|+1)"
+	p.X.Label.Text = "column frequency"
+	p.Y.Label.Text = "row frequency"
+	h := plotter.NewHeatMap(centered{mag}, moreland.ExtendedBlackBody().Palette(256))
+	h.Rasterized = true
+	p.Add(h)
+	c := vgimg.New(15*vg.Centimeter, 12*vg.Centimeter)
+	p.Draw(draw.New(c))
+	show.PNG(c.Image(), "", "")
+
+	/*{md}
+	The image is compressed by keeping only the largest Fourier coefficients
+	and zeroing the rest. Conjugate pairs have equal magnitude, so ranking
+	the stored coefficients keeps the same fraction of the full spectrum.
+	*/
+	sorted := make([]float64, 0, rows*(cols/2+1))
+	for i := 0; i < rows; i++ {
+		for j := 0; j <= cols/2; j++ {
+			sorted = append(sorted, cmplx.Abs(bt.At(i, j)))
+		}
+	}
+	sort.Float64s(sorted)
+
+	low := mat.NewCDense(rows, cols/2+1, nil)
+	alow := mat.NewDense(rows, cols, nil)
+	for _, keep := range []float64{0.1, 0.01, 0.002} {
+		thresh := sorted[int(math.Floor(float64(len(sorted)-1)*(1-keep)))]
+		low.Copy(bt)
+		for i := 0; i < rows; i++ {
+			for j := 0; j <= cols/2; j++ {
+				if cmplx.Abs(low.At(i, j)) < thresh {
+					low.Set(i, j, 0)
+				}
+			}
+		}
+		fft.Sequence(alow, low)
+		alow.Scale(1/float64(rows*cols), alow)
+
+		fmt.Printf("keep %.1f%%: relative error = %.4f\n", 100*keep, relativeError(alow, a))
+		show.JPEG(scaled(imgmat.GrayImage(alow, imgmat.Clamp), 600), nil, "", fmt.Sprintf("Compressed image: keep = %.1f%%", 100*keep))
+	}
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+// centered is a plotter.GridXYZ for a frequency-shifted
+// spectrum with the zero frequency at the center.
+type centered struct {
+	Data mat.Matrix
+}
+
+func (g centered) Dims() (c, r int)   { r, c = g.Data.Dims(); return c, r }
+func (g centered) Z(c, r int) float64 { return g.Data.At(r, c) }
+func (g centered) X(c int) float64 {
+	_, n := g.Data.Dims()
+	if c < 0 || c >= n {
+		panic("column index out of range")
+	}
+	return float64(c - n/2)
+}
+func (g centered) Y(r int) float64 {
+	m, _ := g.Data.Dims()
+	if r < 0 || r >= m {
+		panic("row index out of range")
+	}
+	return float64(r - m/2)
+}
+
+func relativeError(got, want mat.Matrix) float64 {
+	var d mat.Dense
+	d.Sub(got, want)
+	return mat.Norm(&d, 2) / mat.Norm(want, 2)
+}
+
+func scaled(img image.Image, max int) image.Image {
+	rect := img.Bounds()
+	dx, dy := rect.Dx(), rect.Dy()
+	switch {
+	case dx < dy:
+		dx, dy = dx*max/dy, max
+	case dy < dx:
+		dx, dy = max, dy*max/dx
+	default:
+		dx, dy = max, max
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, dx, dy))
+	drawimg.NearestNeighbor.Scale(scaled, scaled.Bounds(), img, rect, drawimg.Over, nil)
+	return scaled
+}
@@ -0,0 +1,186 @@
+//go:generate bash -c "rm -f CH04_SEC04_1_ModelSelection*.png"
+//go:generate gd -o CH04_SEC04_1_ModelSelection.md CH04_SEC04_1_ModelSelection.go
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kortschak/gd/show"
+
+	"github.com/kortschak/databook_gonum/regress"
+	"github.com/kortschak/databook_gonum/seed"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func main() {
+	rnd, err := seed.Rand()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	/*{md}
+	Noisy samples of the parabola f(x) = x² are fitted with polynomials of
+	increasing degree. The information criteria and the 5-fold cross
+	validation error all fall sharply until the degree of the underlying
+	function is reached. Beyond that, additional terms only fit the noise
+	and are penalized most clearly by BIC, which is used to choose the model.
+	*/
+	n, l := 200, 4.0
+	x := floats.Span(make([]float64, n), 0, l)
+	y := make([]float64, n)
+	for i, v := range x {
+		y[i] = v*v + 0.2*rnd.NormFloat64()
+	}
+	var polys []regress.Basis
+	for d := 0; d <= 10; d++ {
+		polys = append(polys, regress.Polynomial(d))
+	}
+	best := selectModel("degree", polys, x, y, rnd)
+	plotFit(fmt.Sprintf("Polynomial degree %d", best), regress.Polynomial(best), x, y)
+
+	/*{md}
+	The same procedure selects the order of a Fourier series fitted to a
+	noisy periodic signal with components at the first and third harmonics.
+	*/
+	for i, v := range x {
+		w := 2 * math.Pi * v / l
+		y[i] = 2*math.Sin(w) + math.Cos(3*w) + 0.2*rnd.NormFloat64()
+	}
+	var series []regress.Basis
+	for k := 0; k <= 10; k++ {
+		series = append(series, regress.Fourier{Order: k, Period: l})
+	}
+	best = selectModel("order", series, x, y, rnd)
+	plotFit(fmt.Sprintf("Fourier order %d", best), regress.Fourier{Order: best, Period: l}, x, y)
+}
+
+/*{md}
+The code below is helper code only.
+*/
+
+// selectModel fits each of the bases to x and y, prints and plots their
+// model selection criteria and returns the index of the basis with the
+// lowest BIC.
+func selectModel(param string, bases []regress.Basis, x, y []float64, rnd *rand.Rand) int {
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\tRSS\tAIC\tBIC\tCV MSE\t\n", param)
+	aic := make(plotter.XYs, len(bases))
+	bic := make(plotter.XYs, len(bases))
+	cv := make(plotter.XYs, len(bases))
+	best := 0
+	for i, b := range bases {
+		fit, err := regress.FitBasis(b, x, y)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mse, err := regress.CrossValidate(regress.Design(b, x), mat.NewVecDense(len(y), y), 5, rnd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		aic[i] = plotter.XY{X: float64(i), Y: fit.AIC()}
+		bic[i] = plotter.XY{X: float64(i), Y: fit.BIC()}
+		cv[i] = plotter.XY{X: float64(i), Y: mse}
+		if bic[i].Y < bic[best].Y {
+			best = i
+		}
+		fmt.Fprintf(w, "%d\t%.3f\t%.1f\t%.1f\t%.4f\t\n", i, fit.RSS, fit.AIC(), fit.BIC(), mse)
+	}
+	w.Flush()
+	fmt.Print(buf.String())
+
+	p1 := plot.New()
+	p1.X.Label.Text = param
+	p1.Y.Label.Text = "Information criterion"
+	p1.Legend.Top = true
+	for _, c := range []struct {
+		name  string
+		xys   plotter.XYs
+		color color.Color
+	}{
+		{name: "AIC", xys: aic, color: color.RGBA{B: 255, A: 255}},
+		{name: "BIC", xys: bic, color: color.RGBA{R: 255, A: 255}},
+	} {
+		l, p, err := plotter.NewLinePoints(c.xys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		l.Color = c.color
+		p.Color = c.color
+		p1.Add(l, p)
+		p1.Legend.Add(c.name, l, p)
+	}
+
+	p2 := plot.New()
+	p2.X.Label.Text = param
+	p2.Y.Label.Text = "Cross validation MSE"
+	p2.Y.Scale = plot.LogScale{}
+	p2.Y.Tick.Marker = plot.LogTicks{}
+	l, p, err := plotter.NewLinePoints(cv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p2.Add(l, p)
+
+	img := vgimg.New(18*vg.Centimeter, 8*vg.Centimeter)
+	plots := [][]*plot.Plot{{p1, p2}}
+	canvases := plot.Align(plots, draw.Tiles{Rows: 1, Cols: 2}, draw.New(img))
+	for i, c := range canvases[0] {
+		plots[0][i].Draw(c)
+	}
+	show.PNG(img.Image(), "", "")
+
+	return best
+}
+
+// plotFit plots the data and the least squares fit of the basis b.
+func plotFit(title string, b regress.Basis, x, y []float64) {
+	fit, err := regress.FitBasis(b, x, y)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p := plot.New()
+	p.Title.Text = title
+	data, err := plotter.NewScatter(slicesToXYs(x, y))
+	if err != nil {
+		log.Fatal(err)
+	}
+	data.GlyphStyle.Color = color.Gray{Y: 128}
+	data.GlyphStyle.Radius = vg.Points(1.5)
+	model, err := plotter.NewLine(slicesToXYs(x, fit.Fitted.RawVector().Data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	model.Color = color.RGBA{R: 255, A: 255}
+	model.Width = vg.Points(2)
+	p.Add(data, model)
+	p.Legend.Top = true
+	p.Legend.Left = true
+	p.Legend.Add("Data", data)
+	p.Legend.Add("Fit", model)
+	c := vgimg.New(12*vg.Centimeter, 8*vg.Centimeter)
+	p.Draw(draw.New(c))
+	show.PNG(c.Image(), "", "")
+}
+
+func slicesToXYs(x, y []float64) plotter.XYs {
+	xy := make(plotter.XYs, len(x))
+	for i := range x {
+		xy[i] = plotter.XY{X: x[i], Y: y[i]}
+	}
+	return xy
+}
@@ -0,0 +1,3 @@
+//go:generate go run ../index.go *SEC*.md
+
+package main
@@ -0,0 +1,282 @@
+// The rundemos command builds and runs each CHxx_SECyy demo program
+// without gd, collecting the images, standard output and timing of each
+// run in an output directory.
+//
+// Each demo is run with its working directory set to the chapter
+// directory under the output directory, so images written by show.PNG and
+// show.JPEG are placed there, and the DATA directory is made available as
+// a sibling of the chapter directories. The standard output and standard
+// error of each demo are written next to its images and a summary of all
+// runs is written to summary.json in the output directory.
+//
+// rundemos exits with a non-zero status if any demo fails to build or run.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kortschak/databook_gonum/seed"
+)
+
+func main() {
+	root := flag.String("root", ".", "specify the repository root")
+	out := flag.String("out", "demo_output", "specify the output directory")
+	run := flag.String("run", "", "run only demos matching the regexp")
+	skip := flag.String("skip", "", "skip demos matching the regexp")
+	timeout := flag.Duration("timeout", 10*time.Minute, "specify the time limit for each demo")
+	seedValue := flag.Int64("seed", 0, "seed for the demo random source (default $"+seed.Env+" or the demo seed)")
+	flag.Parse()
+
+	env := demoEnv(flag.CommandLine, *seedValue)
+
+	runRE, err := regexp.Compile(*run)
+	if err != nil {
+		log.Fatalf("invalid -run pattern: %v", err)
+	}
+	var skipRE *regexp.Regexp
+	if *skip != "" {
+		skipRE, err = regexp.Compile(*skip)
+		if err != nil {
+			log.Fatalf("invalid -skip pattern: %v", err)
+		}
+	}
+
+	demos, err := discover(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var selected []string
+	for _, d := range demos {
+		name := demoName(d)
+		if !runRE.MatchString(name) || (skipRE != nil && skipRE.MatchString(name)) {
+			continue
+		}
+		selected = append(selected, d)
+	}
+	if len(selected) == 0 {
+		log.Fatal("no demos found")
+	}
+
+	r, err := newRunner(*root, *out, *timeout, env)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(r.bin)
+
+	var (
+		results []result
+		failed  bool
+	)
+	for _, d := range selected {
+		res := r.run(d)
+		results = append(results, res)
+		if res.Error != "" {
+			failed = true
+			log.Printf("%s: %s", res.Demo, res.Error)
+		}
+	}
+
+	err = writeSummary(r.out, results)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printTable(os.Stdout, results)
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// demoEnv returns the environment for running demos. The seed is passed
+// to the demos only if the -seed flag was set in fs, so that any seed,
+// including zero, can be specified.
+func demoEnv(fs *flag.FlagSet, value int64) []string {
+	env := os.Environ()
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			env = append(env, seed.Env+"="+strconv.FormatInt(value, 10))
+		}
+	})
+	return env
+}
+
+// writeSummary writes the results as JSON to summary.json in dir.
+func writeSummary(dir string, results []result) error {
+	summary, err := json.MarshalIndent(results, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "summary.json"), append(summary, '\n'), 0o664)
+}
+
+// printTable writes a table of the status and run time of each result
+// to dst.
+func printTable(dst io.Writer, results []result) {
+	w := tabwriter.NewWriter(dst, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "demo\tstatus\ttime")
+	for _, res := range results {
+		status := "ok"
+		if res.Error != "" {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2fs\n", res.Demo, status, res.Seconds)
+	}
+	w.Flush()
+}
+
+// demoPattern matches demo program file names.
+var demoPattern = regexp.MustCompile(`^CH[0-9]{2}_SEC[0-9]{2}.*\.go$`)
+
+// discover returns the paths of all demo programs under root, relative to
+// root, in lexical order.
+func discover(root string) ([]string, error) {
+	chapters, err := filepath.Glob(filepath.Join(root, "CH[0-9][0-9]"))
+	if err != nil {
+		return nil, err
+	}
+	var demos []string
+	for _, dir := range chapters {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			name := f.Name()
+			if f.IsDir() || !demoPattern.MatchString(name) || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			demos = append(demos, filepath.Join(filepath.Base(dir), name))
+		}
+	}
+	return demos, nil
+}
+
+// demoName returns the name of the demo at path.
+func demoName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// result is the outcome of a single demo run.
+type result struct {
+	Demo    string  `json:"demo"`
+	Seconds float64 `json:"seconds"`
+	Stdout  string  `json:"stdout"`
+	Stderr  string  `json:"stderr"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// runner builds and runs demos.
+type runner struct {
+	root    string
+	out     string
+	bin     string
+	timeout time.Duration
+	env     []string
+}
+
+// newRunner returns a runner that builds demos from root and runs them
+// with the given environment, writing their output under out.
+func newRunner(root, out string, timeout time.Duration, env []string) (*runner, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	out, err = filepath.Abs(out)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(out, 0o775)
+	if err != nil {
+		return nil, err
+	}
+
+	// Demos read their data from ../DATA relative to
+	// the chapter directory.
+	data := filepath.Join(out, "DATA")
+	_, err = os.Lstat(data)
+	if os.IsNotExist(err) {
+		err = os.Symlink(filepath.Join(root, "DATA"), data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bin, err := os.MkdirTemp("", "rundemos")
+	if err != nil {
+		return nil, err
+	}
+	return &runner{root: root, out: out, bin: bin, timeout: timeout, env: env}, nil
+}
+
+// run builds and runs the demo at path, relative to the repository root.
+func (r *runner) run(path string) result {
+	name := demoName(path)
+	res := result{Demo: filepath.ToSlash(path)}
+	dir := filepath.Join(r.out, filepath.Dir(path))
+	err := os.MkdirAll(dir, 0o775)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	exe := filepath.Join(r.bin, name)
+	build := exec.Command("go", "build", "-o", exe, "./"+filepath.ToSlash(path))
+	build.Dir = r.root
+	msg, err := build.CombinedOutput()
+	if err != nil {
+		res.Error = fmt.Sprintf("build failed: %v\n%s", err, msg)
+		return res
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe)
+	cmd.Dir = dir
+	cmd.Env = r.env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err = cmd.Run()
+	res.Seconds = time.Since(start).Seconds()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v", r.timeout)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			res.Error += ": " + msg
+		}
+	}
+
+	res.Stdout = filepath.Join(dir, name+".stdout")
+	res.Stderr = filepath.Join(dir, name+".stderr")
+	for _, f := range []struct {
+		path string
+		data []byte
+	}{
+		{path: res.Stdout, data: stdout.Bytes()},
+		{path: res.Stderr, data: stderr.Bytes()},
+	} {
+		werr := os.WriteFile(f.path, f.data, 0o664)
+		if werr != nil && res.Error == "" {
+			res.Error = werr.Error()
+		}
+	}
+	return res
+}
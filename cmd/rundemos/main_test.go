@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kortschak/databook_gonum/seed"
+)
+
+func TestDemoPattern(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want bool
+	}{
+		{name: "CH01_SEC02.go", want: true},
+		{name: "CH01_SEC04_3_Housing.go", want: true},
+		{name: "CH12_SEC01_2_Sparse.go", want: true},
+		{name: "CH01_SEC02.md", want: false},
+		{name: "CH01_SEC02_109.png", want: false},
+		{name: "index.go", want: false},
+		{name: "CH1_SEC02.go", want: false},
+		{name: "CH01SEC02.go", want: false},
+		{name: "xCH01_SEC02.go", want: false},
+	} {
+		if got := demoPattern.MatchString(test.name); got != test.want {
+			t.Errorf("unexpected match for %q: got:%t want:%t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root, err := os.MkdirTemp("", "rundemos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(root)
+	for _, path := range []string{
+		"CH02/CH02_SEC01_1_FFT.go",
+		"CH01/CH01_SEC02.go",
+		"CH01/CH01_SEC01_test.go",
+		"CH01/CH01_SEC02.md",
+		"CH01/index.go",
+		"CH01/CH01_SEC03.go/placeholder",
+		"CHAPTER/CH01_SEC01.go",
+		"cmd/CH01_SEC01.go",
+	} {
+		writeFile(t, filepath.Join(root, filepath.FromSlash(path)), "package main\n")
+	}
+
+	got, err := discover(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		filepath.Join("CH01", "CH01_SEC02.go"),
+		filepath.Join("CH02", "CH02_SEC01_1_FFT.go"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected demos: got:%q want:%q", got, want)
+	}
+	if name := demoName(want[1]); name != "CH02_SEC01_1_FFT" {
+		t.Errorf("unexpected demo name: got:%q want:%q", name, "CH02_SEC01_1_FFT")
+	}
+}
+
+func TestDemoEnv(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want string
+		set  bool
+	}{
+		{args: nil, set: false},
+		{args: []string{"-seed=0"}, want: "0", set: true},
+		{args: []string{"-seed", "42"}, want: "42", set: true},
+	} {
+		fs := flag.NewFlagSet("rundemos", flag.ContinueOnError)
+		value := fs.Int64("seed", 0, "")
+		err := fs.Parse(test.args)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", test.args, err)
+		}
+		env := demoEnv(fs, *value)
+		last, set := "", false
+		for _, kv := range env {
+			if strings.HasPrefix(kv, seed.Env+"=") {
+				last, set = strings.TrimPrefix(kv, seed.Env+"="), true
+			}
+		}
+		if test.set && (!set || last != test.want) {
+			t.Errorf("unexpected seed for %q: got:%q want:%q", test.args, last, test.want)
+		}
+		if !test.set && len(env) != len(os.Environ()) {
+			t.Errorf("unexpected environment change for %q", test.args)
+		}
+	}
+}
+
+const demoSource = `package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	name := filepath.Base(os.Args[0])
+	switch {
+	case strings.HasSuffix(name, "OK"):
+		err := os.WriteFile("image.png", []byte("png"), 0o664)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("seed=%q\n", os.Getenv("DATABOOK_SEED"))
+	case strings.HasSuffix(name, "FAIL"):
+		fmt.Fprintln(os.Stderr, "failure")
+		os.Exit(2)
+	case strings.HasSuffix(name, "WAIT"):
+		time.Sleep(time.Minute)
+	}
+}
+`
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping demo build in short mode")
+	}
+	root, err := os.MkdirTemp("", "rundemos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(root)
+	writeFile(t, filepath.Join(root, "go.mod"), "module example\n\ngo 1.16\n")
+	writeFile(t, filepath.Join(root, "DATA", "data.csv"), "1,2\n")
+	for _, name := range []string{"CH01_SEC01_OK", "CH01_SEC02_FAIL", "CH01_SEC03_WAIT"} {
+		writeFile(t, filepath.Join(root, "CH01", name+".go"), demoSource)
+	}
+	out := filepath.Join(root, "out")
+
+	env := append(os.Environ(), seed.Env+"=0")
+	r, err := newRunner(root, out, 2*time.Second, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(r.bin)
+
+	// The data directory is available to the demos.
+	if _, err := os.Stat(filepath.Join(out, "DATA", "data.csv")); err != nil {
+		t.Errorf("data directory not linked: %v", err)
+	}
+
+	ok := r.run(filepath.Join("CH01", "CH01_SEC01_OK.go"))
+	if ok.Error != "" {
+		t.Errorf("unexpected error: %s", ok.Error)
+	}
+	if ok.Demo != "CH01/CH01_SEC01_OK.go" {
+		t.Errorf("unexpected demo path: got:%q want:%q", ok.Demo, "CH01/CH01_SEC01_OK.go")
+	}
+	stdout, err := os.ReadFile(ok.Stdout)
+	if err != nil {
+		t.Fatalf("unexpected error reading stdout: %v", err)
+	}
+	// A zero seed is passed through to the demo.
+	if got, want := string(stdout), "seed=\"0\"\n"; got != want {
+		t.Errorf("unexpected stdout: got:%q want:%q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(out, "CH01", "image.png")); err != nil {
+		t.Errorf("image not written to chapter directory: %v", err)
+	}
+
+	fail := r.run(filepath.Join("CH01", "CH01_SEC02_FAIL.go"))
+	if !strings.Contains(fail.Error, "exit status 2") || !strings.Contains(fail.Error, "failure") {
+		t.Errorf("unexpected error for failing demo: %q", fail.Error)
+	}
+
+	wait := r.run(filepath.Join("CH01", "CH01_SEC03_WAIT.go"))
+	if !strings.Contains(wait.Error, "timed out") {
+		t.Errorf("unexpected error for slow demo: %q", wait.Error)
+	}
+
+	missing := r.run(filepath.Join("CH01", "CH01_SEC04_MISSING.go"))
+	if !strings.HasPrefix(missing.Error, "build failed") {
+		t.Errorf("unexpected error for missing demo: %q", missing.Error)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	dir, err := os.MkdirTemp("", "rundemos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := []result{
+		{Demo: "CH01/CH01_SEC01.go", Seconds: 1.5, Stdout: "a.stdout", Stderr: "a.stderr"},
+		{Demo: "CH01/CH01_SEC02.go", Seconds: 0.25, Error: "exit status 1"},
+	}
+	err = writeSummary(dir, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "summary.json"))
+	if err != nil {
+		t.Fatalf("unexpected error reading summary: %v", err)
+	}
+	var got []result
+	err = json.Unmarshal(data, &got)
+	if err != nil {
+		t.Fatalf("unexpected error decoding summary: %v", err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("unexpected summary: got:%+v want:%+v", got, results)
+	}
+	if bytes.Count(data, []byte(`"error"`)) != 1 {
+		t.Errorf("error field not omitted for successful run:\n%s", data)
+	}
+
+	var buf bytes.Buffer
+	printTable(&buf, results)
+	want := `demo                status  time
+CH01/CH01_SEC01.go  ok      1.50s
+CH01/CH01_SEC02.go  FAIL    0.25s
+`
+	if buf.String() != want {
+		t.Errorf("unexpected table:\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0o775)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = os.WriteFile(path, []byte(data), 0o664)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
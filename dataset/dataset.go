@@ -0,0 +1,286 @@
+// Package dataset provides loaders for the delimited text data sets
+// used by the demos.
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Options specifies how delimited text data is parsed.
+type Options struct {
+	// Comma is the field delimiter. If Comma is zero,
+	// fields are separated by runs of white space.
+	Comma rune
+
+	// Comment is the comment character. If Comment is
+	// not zero, lines beginning with Comment are skipped.
+	// Leading white space is ignored only for white
+	// space-separated data.
+	Comment rune
+
+	// Header specifies that the first record is a
+	// header holding column names.
+	Header bool
+
+	// Columns is the set of column indices to load, in
+	// the order they will be placed in the destination.
+	// If Columns is nil, all columns are loaded.
+	Columns []int
+
+	// Missing is the set of field values that mark a
+	// missing value, for example "?" or "NA". Fields
+	// are compared after trimming white space. Missing
+	// numeric values are loaded as NaN.
+	Missing []string
+}
+
+// CSV is the Options for comma-separated data without a header.
+var CSV = Options{Comma: ','}
+
+// Text is the Options for white space-separated data without a header.
+var Text = Options{}
+
+// RaggedError is returned when a record does not have the same number
+// of fields as the first record.
+type RaggedError struct {
+	Line      int // Line is the 1-based line number of the record.
+	Got, Want int // Got and Want are the number of fields found and expected.
+}
+
+func (e *RaggedError) Error() string {
+	return fmt.Sprintf("dataset: line %d: ragged row: got %d fields, want %d", e.Line, e.Got, e.Want)
+}
+
+// ParseError is returned when a field cannot be parsed.
+type ParseError struct {
+	Line   int   // Line is the 1-based line number of the record.
+	Column int   // Column is the 0-based column index of the field.
+	Err    error // Err is the underlying error.
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("dataset: line %d: column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ErrEmpty is returned when no data records are found.
+var ErrEmpty = errors.New("dataset: no data")
+
+// Records reads the delimited records from r according to opt. It returns
+// the header, if opt.Header is true, the selected fields of each record and
+// the line number of each record. All records are checked to have the same
+// number of fields.
+//
+// Comma-separated data is parsed with encoding/csv, so quoted fields may
+// contain delimiters and span lines. The line number of such a record is
+// the line on which it starts.
+func Records(r io.Reader, opt Options) (header []string, records [][]string, lines []int, err error) {
+	c := collector{opt: opt, width: -1}
+	if opt.Comma == 0 {
+		err = c.fields(r)
+	} else {
+		err = c.csv(r)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return c.header, c.records, c.lines, nil
+}
+
+// collector accumulates records, checking their widths and
+// selecting columns.
+type collector struct {
+	opt     Options
+	width   int
+	header  []string
+	records [][]string
+	lines   []int
+}
+
+// fields reads white space-separated records from r.
+func (c *collector) fields(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if c.opt.Comment != 0 && strings.HasPrefix(text, string(c.opt.Comment)) {
+			continue
+		}
+		err := c.add(strings.Fields(text), line)
+		if err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// csv reads delimited records from r.
+func (c *collector) csv(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.Comma = c.opt.Comma
+	cr.Comment = c.opt.Comment
+	cr.FieldsPerRecord = -1
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return &ParseError{Line: perr.Line, Err: perr.Err}
+			}
+			return err
+		}
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+			// Lines holding only white space.
+			continue
+		}
+		for i, f := range fields {
+			fields[i] = strings.TrimSpace(f)
+		}
+		line, _ := cr.FieldPos(0)
+		err = c.add(fields, line)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// add adds the record read from the given line, or sets the header if
+// it is the first record and a header is expected.
+func (c *collector) add(fields []string, line int) error {
+	if c.width < 0 {
+		c.width = len(fields)
+		for _, col := range c.opt.Columns {
+			if col < 0 || col >= c.width {
+				return fmt.Errorf("dataset: line %d: column %d out of range [0,%d)", line, col, c.width)
+			}
+		}
+	} else if len(fields) != c.width {
+		return &RaggedError{Line: line, Got: len(fields), Want: c.width}
+	}
+	fields = selectColumns(fields, c.opt.Columns)
+	if c.opt.Header && c.header == nil {
+		c.header = fields
+		return nil
+	}
+	c.records = append(c.records, fields)
+	c.lines = append(c.lines, line)
+	return nil
+}
+
+func selectColumns(fields []string, cols []int) []string {
+	if cols == nil {
+		return fields
+	}
+	sel := make([]string, len(cols))
+	for i, c := range cols {
+		sel[i] = fields[c]
+	}
+	return sel
+}
+
+// Read reads numeric delimited data from r into dst according to opt and
+// returns the header if opt.Header is true. The destination must be a
+// *mat.Dense or a *mat.VecDense, and is resized to hold the data. When dst
+// is a *mat.VecDense only the first selected column is loaded.
+func Read(dst mat.Matrix, r io.Reader, opt Options) (header []string, err error) {
+	header, records, lines, err := Records(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) == 0 {
+		return header, ErrEmpty
+	}
+	switch dst := dst.(type) {
+	case *mat.Dense:
+		rows, cols := len(records), len(records[0])
+		data := make([]float64, 0, rows*cols)
+		for i, rec := range records {
+			for j, s := range rec {
+				v, err := parseFloat(s, opt.Missing)
+				if err != nil {
+					return header, &ParseError{Line: lines[i], Column: column(j, opt.Columns), Err: err}
+				}
+				data = append(data, v)
+			}
+		}
+		dst.CloneFrom(mat.NewDense(rows, cols, data))
+	case *mat.VecDense:
+		data := make([]float64, len(records))
+		for i, rec := range records {
+			v, err := parseFloat(rec[0], opt.Missing)
+			if err != nil {
+				return header, &ParseError{Line: lines[i], Column: column(0, opt.Columns), Err: err}
+			}
+			data[i] = v
+		}
+		dst.CloneFromVec(mat.NewVecDense(len(data), data))
+	default:
+		return header, fmt.Errorf("dataset: unsupported destination type %T", dst)
+	}
+	return header, nil
+}
+
+// parseFloat parses s as a float64, returning NaN if s is a missing
+// value marker.
+func parseFloat(s string, missing []string) (float64, error) {
+	for _, m := range missing {
+		if s == m {
+			return math.NaN(), nil
+		}
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// column returns the source column index of the jth selected column.
+func column(j int, cols []int) int {
+	if cols == nil {
+		return j
+	}
+	return cols[j]
+}
+
+// ReadFile reads numeric delimited data from the file at path into dst
+// according to opt. See Read for details.
+func ReadFile(dst mat.Matrix, path string, opt Options) (header []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	header, err = Read(dst, f, opt)
+	if err != nil {
+		return header, fmt.Errorf("%s: %w", path, err)
+	}
+	return header, nil
+}
+
+// ReadCSV reads comma-separated numeric data without a header from the
+// file at path into dst.
+func ReadCSV(dst mat.Matrix, path string) error {
+	_, err := ReadFile(dst, path, CSV)
+	return err
+}
+
+// ReadText reads white space-separated numeric data without a header from
+// the file at path into dst.
+func ReadText(dst mat.Matrix, path string) error {
+	_, err := ReadFile(dst, path, Text)
+	return err
+}
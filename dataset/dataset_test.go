@@ -0,0 +1,196 @@
+package dataset
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var recordsTests = []struct {
+	name    string
+	data    string
+	opt     Options
+	header  []string
+	records [][]string
+	lines   []int
+}{
+	{
+		name:    "csv",
+		data:    "1,2,3\n4,5,6\n",
+		opt:     CSV,
+		records: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+		lines:   []int{1, 2},
+	},
+	{
+		name:    "text",
+		data:    "  1 2\t3\n\n4  5 6",
+		opt:     Text,
+		records: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+		lines:   []int{1, 3},
+	},
+	{
+		name:    "header",
+		data:    "a, b, c\n1, 2, 3\n",
+		opt:     Options{Comma: ',', Header: true},
+		header:  []string{"a", "b", "c"},
+		records: [][]string{{"1", "2", "3"}},
+		lines:   []int{2},
+	},
+	{
+		name:    "csv comments",
+		data:    "# comment\n1,2\n\n#1,2,3\n3,4\n",
+		opt:     Options{Comma: ',', Comment: '#'},
+		records: [][]string{{"1", "2"}, {"3", "4"}},
+		lines:   []int{2, 5},
+	},
+	{
+		name:    "text comments",
+		data:    "  % comment\n1 2\n% 1 2 3\n3 4\n",
+		opt:     Options{Comment: '%'},
+		records: [][]string{{"1", "2"}, {"3", "4"}},
+		lines:   []int{2, 4},
+	},
+	{
+		name:    "columns",
+		data:    "h0,h1,h2\n1,2,3\n4,5,6\n",
+		opt:     Options{Comma: ',', Header: true, Columns: []int{2, 0}},
+		header:  []string{"h2", "h0"},
+		records: [][]string{{"3", "1"}, {"6", "4"}},
+		lines:   []int{2, 3},
+	},
+	{
+		name:    "quoted",
+		data:    "a,\"b,\nc\"\nd,e\n",
+		opt:     CSV,
+		records: [][]string{{"a", "b,\nc"}, {"d", "e"}},
+		lines:   []int{1, 3},
+	},
+}
+
+func TestRecords(t *testing.T) {
+	for _, test := range recordsTests {
+		header, records, lines, err := Records(strings.NewReader(test.data), test.opt)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(header, test.header) {
+			t.Errorf("unexpected header for %s: got:%q want:%q", test.name, header, test.header)
+		}
+		if !reflect.DeepEqual(records, test.records) {
+			t.Errorf("unexpected records for %s: got:%q want:%q", test.name, records, test.records)
+		}
+		if !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("unexpected lines for %s: got:%d want:%d", test.name, lines, test.lines)
+		}
+	}
+}
+
+func TestRecordsRagged(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data string
+		opt  Options
+		want RaggedError
+	}{
+		{name: "csv", data: "1,2\n3,4\n\n5\n", opt: CSV, want: RaggedError{Line: 4, Got: 1, Want: 2}},
+		{name: "text", data: "1 2\n# x\n3 4 5\n", opt: Options{Comment: '#'}, want: RaggedError{Line: 3, Got: 3, Want: 2}},
+		{name: "quoted", data: "1,\"a\nb\"\n3,4,5\n", opt: CSV, want: RaggedError{Line: 3, Got: 3, Want: 2}},
+	} {
+		_, _, _, err := Records(strings.NewReader(test.data), test.opt)
+		var got *RaggedError
+		if !errors.As(err, &got) {
+			t.Errorf("expected ragged error for %s: got:%v", test.name, err)
+			continue
+		}
+		if *got != test.want {
+			t.Errorf("unexpected ragged error for %s: got:%+v want:%+v", test.name, *got, test.want)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	const data = "x,y,z\n1,2,3\n4,?,6\n"
+	opt := Options{Comma: ',', Header: true, Missing: []string{"?"}}
+
+	var m mat.Dense
+	header, err := Read(&m, strings.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"x", "y", "z"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("unexpected header: got:%q want:%q", header, want)
+	}
+	want := mat.NewDense(2, 3, []float64{1, 2, 3, 4, math.NaN(), 6})
+	if !equalNaN(&m, want) {
+		t.Errorf("unexpected matrix:\ngot: %v\nwant:%v", mat.Formatted(&m), mat.Formatted(want))
+	}
+
+	var v mat.VecDense
+	opt.Columns = []int{2}
+	_, err = Read(&v, strings.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wantVec := mat.NewVecDense(2, []float64{3, 6}); !mat.Equal(&v, wantVec) {
+		t.Errorf("unexpected vector: got:%v want:%v", v.RawVector().Data, wantVec.RawVector().Data)
+	}
+
+	_, err = Read(&m, strings.NewReader("1,2\n3,x\n"), CSV)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected parse error: got:%v", err)
+	}
+	if perr.Line != 2 || perr.Column != 1 {
+		t.Errorf("unexpected parse error position: got:line %d column %d want:line 2 column 1", perr.Line, perr.Column)
+	}
+
+	_, err = Read(&m, strings.NewReader("# only a comment\n"), Options{Comma: ',', Comment: '#'})
+	if err != ErrEmpty {
+		t.Errorf("unexpected error for empty data: got:%v want:%v", err, ErrEmpty)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	for _, test := range []struct {
+		path       string
+		read       func(mat.Matrix, string) error
+		rows, cols int
+	}{
+		{path: "hald_ingredients.csv", read: ReadCSV, rows: 13, cols: 4},
+		{path: "hald_heat.csv", read: ReadCSV, rows: 13, cols: 1},
+		{path: "housing.data", read: ReadText, rows: 506, cols: 14},
+	} {
+		var m mat.Dense
+		err := test.read(&m, filepath.Join("..", "DATA", test.path))
+		if err != nil {
+			t.Errorf("unexpected error reading %s: %v", test.path, err)
+			continue
+		}
+		if r, c := m.Dims(); r != test.rows || c != test.cols {
+			t.Errorf("unexpected dimensions for %s: got:%d×%d want:%d×%d", test.path, r, c, test.rows, test.cols)
+		}
+	}
+}
+
+// equalNaN returns whether a and b are equal, treating NaN values as equal.
+func equalNaN(a, b mat.Matrix) bool {
+	r, c := a.Dims()
+	if br, bc := b.Dims(); r != br || c != bc {
+		return false
+	}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			x, y := a.At(i, j), b.At(i, j)
+			if x != y && !(math.IsNaN(x) && math.IsNaN(y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
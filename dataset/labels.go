@@ -0,0 +1,208 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Dict is a reversible mapping between class labels and class indices.
+type Dict struct {
+	labels []string
+	index  map[string]int
+}
+
+// NewDict returns a Dict holding the given labels. Class indices are
+// assigned in order of first appearance, as done by MATLAB's grp2idx.
+func NewDict(labels ...string) *Dict {
+	d := &Dict{index: make(map[string]int)}
+	for _, l := range labels {
+		d.Add(l)
+	}
+	return d
+}
+
+// Add adds the label to the dictionary if it is not already present,
+// and returns its class index.
+func (d *Dict) Add(label string) int {
+	if i, ok := d.index[label]; ok {
+		return i
+	}
+	if d.index == nil {
+		d.index = make(map[string]int)
+	}
+	i := len(d.labels)
+	d.labels = append(d.labels, label)
+	d.index[label] = i
+	return i
+}
+
+// Index returns the class index of the label and whether the label
+// is in the dictionary.
+func (d *Dict) Index(label string) (int, bool) {
+	i, ok := d.index[label]
+	return i, ok
+}
+
+// Label returns the label of the class index i. Label panics if i is
+// out of range.
+func (d *Dict) Label(i int) string {
+	return d.labels[i]
+}
+
+// Len returns the number of classes in the dictionary.
+func (d *Dict) Len() int {
+	return len(d.labels)
+}
+
+// Labels returns the labels in class index order.
+func (d *Dict) Labels() []string {
+	return append([]string(nil), d.labels...)
+}
+
+// Encode returns the class indices of the given labels, adding any
+// labels not already in the dictionary.
+func (d *Dict) Encode(labels []string) []int {
+	idx := make([]int, len(labels))
+	for i, l := range labels {
+		idx[i] = d.Add(l)
+	}
+	return idx
+}
+
+// Decode returns the labels of the given class indices.
+func (d *Dict) Decode(idx []int) []string {
+	labels := make([]string, len(idx))
+	for i, k := range idx {
+		labels[i] = d.labels[k]
+	}
+	return labels
+}
+
+// ReadLabels reads a column of string class labels from r according to
+// opt and returns the class index of each record and the dictionary
+// mapping between labels and indices. The first selected column is used.
+func ReadLabels(r io.Reader, opt Options) ([]int, *Dict, error) {
+	_, records, _, err := Records(r, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 || len(records[0]) == 0 {
+		return nil, nil, ErrEmpty
+	}
+	labels := make([]string, len(records))
+	for i, rec := range records {
+		labels[i] = rec[0]
+	}
+	d := NewDict()
+	return d.Encode(labels), d, nil
+}
+
+// ReadLabelsFile reads a column of string class labels from the file at
+// path according to opt. See ReadLabels for details.
+func ReadLabelsFile(path string, opt Options) ([]int, *Dict, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	y, d, err := ReadLabels(f, opt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return y, d, nil
+}
+
+// Labelled is a set of observations with class labels.
+type Labelled struct {
+	// X holds the observations in rows.
+	X *mat.Dense
+
+	// Y holds the class index of each row of X.
+	Y []int
+
+	// Dict maps between class indices and labels.
+	Dict *Dict
+}
+
+// NewLabelled returns a Labelled pairing the observations in x with the
+// class indices in y. The number of rows of x must match the length of y
+// and all indices must be valid in d.
+func NewLabelled(x *mat.Dense, y []int, d *Dict) (*Labelled, error) {
+	r, _ := x.Dims()
+	if r != len(y) {
+		return nil, fmt.Errorf("dataset: %d observations but %d labels", r, len(y))
+	}
+	for i, k := range y {
+		if k < 0 || k >= d.Len() {
+			return nil, fmt.Errorf("dataset: label %d: class index %d out of range", i, k)
+		}
+	}
+	return &Labelled{X: x, Y: y, Dict: d}, nil
+}
+
+// ReadLabelled reads observations from the file at obsPath according to
+// obsOpt and class labels from the file at grpPath according to grpOpt,
+// and returns them paired. If transpose is true, the observations file
+// holds observations in columns rather than rows.
+func ReadLabelled(obsPath string, obsOpt Options, grpPath string, grpOpt Options, transpose bool) (*Labelled, error) {
+	x := &mat.Dense{}
+	_, err := ReadFile(x, obsPath, obsOpt)
+	if err != nil {
+		return nil, err
+	}
+	if transpose {
+		var t mat.Dense
+		t.CloneFrom(x.T())
+		x = &t
+	}
+	y, d, err := ReadLabelsFile(grpPath, grpOpt)
+	if err != nil {
+		return nil, err
+	}
+	return NewLabelled(x, y, d)
+}
+
+// Mask returns a boolean group vector that is true for each observation
+// with the given label.
+func (l *Labelled) Mask(label string) []bool {
+	mask := make([]bool, len(l.Y))
+	k, ok := l.Dict.Index(label)
+	if !ok {
+		return mask
+	}
+	for i, c := range l.Y {
+		mask[i] = c == k
+	}
+	return mask
+}
+
+// Group returns the observations with the given class index.
+func (l *Labelled) Group(k int) *mat.Dense {
+	var rows []int
+	for i, c := range l.Y {
+		if c == k {
+			rows = append(rows, i)
+		}
+	}
+	_, cols := l.X.Dims()
+	if len(rows) == 0 {
+		return &mat.Dense{}
+	}
+	g := mat.NewDense(len(rows), cols, nil)
+	for i, r := range rows {
+		g.SetRow(i, l.X.RawRowView(r))
+	}
+	return g
+}
+
+// Counts returns the number of observations in each class.
+func (l *Labelled) Counts() []int {
+	n := make([]int, l.Dict.Len())
+	for _, k := range l.Y {
+		n[k]++
+	}
+	return n
+}
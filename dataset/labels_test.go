@@ -0,0 +1,126 @@
+package dataset
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDict(t *testing.T) {
+	d := NewDict("Normal", "Cancer", "Normal")
+	if d.Len() != 2 {
+		t.Errorf("unexpected number of classes: got:%d want:2", d.Len())
+	}
+	if want := []string{"Normal", "Cancer"}; !reflect.DeepEqual(d.Labels(), want) {
+		t.Errorf("unexpected labels: got:%q want:%q", d.Labels(), want)
+	}
+	idx := d.Encode([]string{"Cancer", "Other", "Normal", "Other"})
+	if want := []int{1, 2, 0, 2}; !reflect.DeepEqual(idx, want) {
+		t.Errorf("unexpected encoding: got:%d want:%d", idx, want)
+	}
+	if got, want := d.Decode(idx), []string{"Cancer", "Other", "Normal", "Other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected decoding: got:%q want:%q", got, want)
+	}
+	if i, ok := d.Index("Other"); !ok || i != 2 {
+		t.Errorf("unexpected index: got:%d,%t want:2,true", i, ok)
+	}
+	if _, ok := d.Index("Absent"); ok {
+		t.Error("unexpected index for absent label")
+	}
+	if d.Label(1) != "Cancer" {
+		t.Errorf("unexpected label: got:%q want:%q", d.Label(1), "Cancer")
+	}
+
+	var zero Dict
+	if i := zero.Add("a"); i != 0 || zero.Len() != 1 {
+		t.Errorf("unexpected zero value behaviour: got index %d length %d", i, zero.Len())
+	}
+}
+
+func TestLabelled(t *testing.T) {
+	y, d, err := ReadLabels(strings.NewReader("b\na\nb\nc\nb\n"), CSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{0, 1, 0, 2, 0}; !reflect.DeepEqual(y, want) {
+		t.Errorf("unexpected class indices: got:%d want:%d", y, want)
+	}
+	x := mat.NewDense(5, 2, []float64{
+		0, 1,
+		2, 3,
+		4, 5,
+		6, 7,
+		8, 9,
+	})
+	l, err := NewLabelled(x, y, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		label string
+		mask  []bool
+	}{
+		{label: "a", mask: []bool{false, true, false, false, false}},
+		{label: "b", mask: []bool{true, false, true, false, true}},
+		{label: "z", mask: []bool{false, false, false, false, false}},
+	} {
+		if got := l.Mask(test.label); !reflect.DeepEqual(got, test.mask) {
+			t.Errorf("unexpected mask for %q: got:%t want:%t", test.label, got, test.mask)
+		}
+	}
+
+	for _, test := range []struct {
+		k    int
+		want *mat.Dense
+	}{
+		{k: 0, want: mat.NewDense(3, 2, []float64{0, 1, 4, 5, 8, 9})},
+		{k: 1, want: mat.NewDense(1, 2, []float64{2, 3})},
+		{k: 2, want: mat.NewDense(1, 2, []float64{6, 7})},
+	} {
+		if got := l.Group(test.k); !mat.Equal(got, test.want) {
+			t.Errorf("unexpected group %d:\ngot: %v\nwant:%v", test.k, mat.Formatted(got), mat.Formatted(test.want))
+		}
+	}
+	if g := l.Group(3); !g.IsEmpty() {
+		t.Errorf("unexpected non-empty group for absent class")
+	}
+
+	if want := []int{3, 1, 1}; !reflect.DeepEqual(l.Counts(), want) {
+		t.Errorf("unexpected counts: got:%d want:%d", l.Counts(), want)
+	}
+
+	for _, test := range []struct {
+		name string
+		x    *mat.Dense
+		y    []int
+	}{
+		{name: "short labels", x: x, y: y[:4]},
+		{name: "invalid index", x: x, y: []int{0, 1, 0, 3, 0}},
+	} {
+		_, err := NewLabelled(test.x, test.y, d)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestReadOvarianGroups(t *testing.T) {
+	y, d, err := ReadLabelsFile(filepath.FromSlash("../DATA/ovariancancer_grp.csv"), CSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Cancer", "Normal"}; !reflect.DeepEqual(d.Labels(), want) {
+		t.Errorf("unexpected labels: got:%q want:%q", d.Labels(), want)
+	}
+	counts := make([]int, d.Len())
+	for _, k := range y {
+		counts[k]++
+	}
+	if want := []int{121, 95}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("unexpected class counts: got:%d want:%d", counts, want)
+	}
+}
@@ -0,0 +1,362 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Kind is the type of data held by a table column.
+type Kind int
+
+const (
+	// Numeric columns hold real values.
+	Numeric Kind = iota
+	// Categorical columns hold string levels.
+	Categorical
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Numeric:
+		return "numeric"
+	case Categorical:
+		return "categorical"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Missing is the code used for missing values in categorical columns.
+const Missing = -1
+
+// Column is a single typed column of a Table.
+type Column struct {
+	Name string
+	Kind Kind
+
+	// Values holds the values of a numeric column.
+	// Missing values are NaN.
+	Values []float64
+
+	// Levels holds the distinct values of a categorical
+	// column and Codes holds the index into Levels for
+	// each row. Missing values have the code Missing.
+	Levels []string
+	Codes  []int
+}
+
+// Len returns the number of rows in the column.
+func (c *Column) Len() int {
+	if c.Kind == Numeric {
+		return len(c.Values)
+	}
+	return len(c.Codes)
+}
+
+// IsMissing returns whether the value in row i is missing.
+func (c *Column) IsMissing(i int) bool {
+	if c.Kind == Numeric {
+		return math.IsNaN(c.Values[i])
+	}
+	return c.Codes[i] == Missing
+}
+
+// Reorder sets the order of the levels of a categorical column, which
+// determines the ordinal and one-hot encoding of the column. The given
+// levels must be a permutation of the existing levels.
+func (c *Column) Reorder(levels []string) error {
+	if c.Kind != Categorical {
+		return fmt.Errorf("dataset: column %q is not categorical", c.Name)
+	}
+	if len(levels) != len(c.Levels) {
+		return fmt.Errorf("dataset: column %q has %d levels, got %d", c.Name, len(c.Levels), len(levels))
+	}
+	idx := make(map[string]int, len(levels))
+	for i, l := range levels {
+		idx[l] = i
+	}
+	remap := make([]int, len(c.Levels))
+	for i, l := range c.Levels {
+		j, ok := idx[l]
+		if !ok {
+			return fmt.Errorf("dataset: column %q: level %q not in new levels", c.Name, l)
+		}
+		remap[i] = j
+	}
+	for i, code := range c.Codes {
+		if code != Missing {
+			c.Codes[i] = remap[code]
+		}
+	}
+	c.Levels = append(c.Levels[:0], levels...)
+	return nil
+}
+
+// Table is a data table with typed columns.
+type Table struct {
+	Columns []*Column
+}
+
+// ReadTable reads a table from delimited text in r according to opt.
+// Column names are taken from the header if opt.Header is true, and are
+// otherwise the column indices. Fields matching any of opt.Missing are
+// treated as missing. A column is numeric if all its non-missing fields
+// parse as numbers, and categorical otherwise. Categorical levels are
+// sorted lexically.
+func ReadTable(r io.Reader, opt Options) (*Table, error) {
+	header, records, _, err := Records(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) == 0 {
+		return nil, ErrEmpty
+	}
+	missing := make(map[string]bool, len(opt.Missing))
+	for _, m := range opt.Missing {
+		missing[m] = true
+	}
+
+	t := &Table{Columns: make([]*Column, len(records[0]))}
+	for j := range t.Columns {
+		name := strconv.Itoa(column(j, opt.Columns))
+		if header != nil {
+			name = header[j]
+		}
+		col := &Column{Name: name, Kind: Numeric, Values: make([]float64, len(records))}
+		for i, rec := range records {
+			s := rec[j]
+			if missing[s] {
+				col.Values[i] = math.NaN()
+				continue
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				col.Kind = Categorical
+				col.Values = nil
+				break
+			}
+			col.Values[i] = v
+		}
+		if col.Kind == Categorical {
+			levels := make(map[string]int)
+			for _, rec := range records {
+				if s := rec[j]; !missing[s] {
+					levels[s] = 0
+				}
+			}
+			col.Levels = make([]string, 0, len(levels))
+			for l := range levels {
+				col.Levels = append(col.Levels, l)
+			}
+			sort.Strings(col.Levels)
+			for k, l := range col.Levels {
+				levels[l] = k
+			}
+			col.Codes = make([]int, len(records))
+			for i, rec := range records {
+				if s := rec[j]; missing[s] {
+					col.Codes[i] = Missing
+				} else {
+					col.Codes[i] = levels[s]
+				}
+			}
+		}
+		t.Columns[j] = col
+	}
+	return t, nil
+}
+
+// ReadTableFile reads a table from the file at path according to opt.
+// See ReadTable for details.
+func ReadTableFile(path string, opt Options) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t, err := ReadTable(f, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Rows returns the number of rows in the table.
+func (t *Table) Rows() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+	return t.Columns[0].Len()
+}
+
+// Column returns the named column, or nil if it does not exist.
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// DropMissing returns a new table holding only the rows of t that have
+// no missing values in the named columns. If no names are given, all
+// columns are considered.
+func (t *Table) DropMissing(names ...string) (*Table, error) {
+	cols, err := t.lookup(names)
+	if err != nil {
+		return nil, err
+	}
+	var keep []int
+	for i := 0; i < t.Rows(); i++ {
+		ok := true
+		for _, c := range cols {
+			if c.IsMissing(i) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			keep = append(keep, i)
+		}
+	}
+	dst := &Table{Columns: make([]*Column, len(t.Columns))}
+	for j, c := range t.Columns {
+		n := &Column{Name: c.Name, Kind: c.Kind}
+		if c.Kind == Numeric {
+			n.Values = make([]float64, len(keep))
+			for k, i := range keep {
+				n.Values[k] = c.Values[i]
+			}
+		} else {
+			n.Levels = append([]string(nil), c.Levels...)
+			n.Codes = make([]int, len(keep))
+			for k, i := range keep {
+				n.Codes[k] = c.Codes[i]
+			}
+		}
+		dst.Columns[j] = n
+	}
+	return dst, nil
+}
+
+func (t *Table) lookup(names []string) ([]*Column, error) {
+	if len(names) == 0 {
+		return t.Columns, nil
+	}
+	cols := make([]*Column, len(names))
+	for i, n := range names {
+		cols[i] = t.Column(n)
+		if cols[i] == nil {
+			return nil, fmt.Errorf("dataset: no column %q", n)
+		}
+	}
+	return cols, nil
+}
+
+// Encoding specifies how categorical columns are encoded in a
+// design matrix.
+type Encoding int
+
+const (
+	// OneHot encodes a categorical column as one indicator
+	// column per level.
+	OneHot Encoding = iota
+	// Dummy encodes a categorical column as one indicator
+	// column per level excluding the first level, avoiding
+	// collinearity with an intercept column.
+	Dummy
+	// Ordinal encodes a categorical column as a single
+	// column holding the level index.
+	Ordinal
+)
+
+// Design returns a design matrix built from the named columns of t, and
+// the names of the design matrix columns. If no names are given, all
+// columns are used. Numeric columns are copied directly and categorical
+// columns are encoded according to enc. Missing values are NaN, except
+// for indicator encodings where all the indicators of a missing value
+// are zero.
+func (t *Table) Design(enc Encoding, names ...string) (*mat.Dense, []string, error) {
+	cols, err := t.lookup(names)
+	if err != nil {
+		return nil, nil, err
+	}
+	var labels []string
+	for _, c := range cols {
+		switch {
+		case c.Kind == Numeric, enc == Ordinal:
+			labels = append(labels, c.Name)
+		default:
+			levels := c.Levels
+			if enc == Dummy && len(levels) != 0 {
+				levels = levels[1:]
+			}
+			for _, l := range levels {
+				labels = append(labels, c.Name+"="+l)
+			}
+		}
+	}
+	rows := t.Rows()
+	if rows == 0 || len(labels) == 0 {
+		return nil, nil, ErrEmpty
+	}
+
+	d := mat.NewDense(rows, len(labels), nil)
+	j := 0
+	for _, c := range cols {
+		switch {
+		case c.Kind == Numeric:
+			d.SetCol(j, c.Values)
+			j++
+		case enc == Ordinal:
+			for i, code := range c.Codes {
+				v := float64(code)
+				if code == Missing {
+					v = math.NaN()
+				}
+				d.Set(i, j, v)
+			}
+			j++
+		default:
+			offset := 0
+			if enc == Dummy && len(c.Levels) != 0 {
+				offset = 1
+			}
+			for i, code := range c.Codes {
+				if code-offset >= 0 {
+					d.Set(i, j+code-offset, 1)
+				}
+			}
+			j += len(c.Levels) - offset
+		}
+	}
+	return d, labels, nil
+}
+
+// Labels returns the codes of the named categorical column as a vector of
+// class indices, and the class names corresponding to each index.
+func (t *Table) Labels(name string) (*mat.VecDense, []string, error) {
+	c := t.Column(name)
+	if c == nil {
+		return nil, nil, fmt.Errorf("dataset: no column %q", name)
+	}
+	if c.Kind != Categorical {
+		return nil, nil, fmt.Errorf("dataset: column %q is not categorical", name)
+	}
+	v := mat.NewVecDense(len(c.Codes), nil)
+	for i, code := range c.Codes {
+		if code == Missing {
+			v.SetVec(i, math.NaN())
+		} else {
+			v.SetVec(i, float64(code))
+		}
+	}
+	return v, append([]string(nil), c.Levels...), nil
+}
@@ -0,0 +1,264 @@
+package dataset
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const tableData = `size, colour, count
+1.5, red, 3
+2, ?, 4
+?, blue, 5
+3, red, 6
+`
+
+var tableOpt = Options{Comma: ',', Header: true, Missing: []string{"?"}}
+
+func TestReadTable(t *testing.T) {
+	tab, err := ReadTable(strings.NewReader(tableData), tableOpt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tab.Rows() != 4 {
+		t.Errorf("unexpected number of rows: got:%d want:4", tab.Rows())
+	}
+	for _, want := range []struct {
+		name    string
+		kind    Kind
+		levels  []string
+		codes   []int
+		missing []bool
+	}{
+		{name: "size", kind: Numeric, missing: []bool{false, false, true, false}},
+		{name: "colour", kind: Categorical, levels: []string{"blue", "red"}, codes: []int{1, Missing, 0, 1}, missing: []bool{false, true, false, false}},
+		{name: "count", kind: Numeric, missing: []bool{false, false, false, false}},
+	} {
+		c := tab.Column(want.name)
+		if c == nil {
+			t.Errorf("missing column %q", want.name)
+			continue
+		}
+		if c.Kind != want.kind {
+			t.Errorf("unexpected kind for %q: got:%v want:%v", want.name, c.Kind, want.kind)
+		}
+		if want.kind == Categorical {
+			if !reflect.DeepEqual(c.Levels, want.levels) {
+				t.Errorf("unexpected levels for %q: got:%q want:%q", want.name, c.Levels, want.levels)
+			}
+			if !reflect.DeepEqual(c.Codes, want.codes) {
+				t.Errorf("unexpected codes for %q: got:%d want:%d", want.name, c.Codes, want.codes)
+			}
+		}
+		for i, m := range want.missing {
+			if c.IsMissing(i) != m {
+				t.Errorf("unexpected missing state for %q row %d: got:%t want:%t", want.name, i, c.IsMissing(i), m)
+			}
+		}
+	}
+	if tab.Column("weight") != nil {
+		t.Error("unexpected column for missing name")
+	}
+}
+
+func TestDropMissing(t *testing.T) {
+	tab, err := ReadTable(strings.NewReader(tableData), tableOpt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		names []string
+		count []float64
+	}{
+		{names: nil, count: []float64{3, 6}},
+		{names: []string{"colour"}, count: []float64{3, 5, 6}},
+		{names: []string{"size"}, count: []float64{3, 4, 6}},
+		{names: []string{"count"}, count: []float64{3, 4, 5, 6}},
+	} {
+		got, err := tab.DropMissing(test.names...)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.names, err)
+			continue
+		}
+		if c := got.Column("count").Values; !reflect.DeepEqual(c, test.count) {
+			t.Errorf("unexpected rows kept for %q: got:%v want:%v", test.names, c, test.count)
+		}
+	}
+	_, err = tab.DropMissing("weight")
+	if err == nil {
+		t.Error("expected error for missing column")
+	}
+}
+
+func TestDesign(t *testing.T) {
+	tab, err := ReadTable(strings.NewReader(tableData), tableOpt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nan := math.NaN()
+	for _, test := range []struct {
+		enc    Encoding
+		labels []string
+		want   *mat.Dense
+	}{
+		{
+			enc:    OneHot,
+			labels: []string{"colour=blue", "colour=red", "count"},
+			want: mat.NewDense(4, 3, []float64{
+				0, 1, 3,
+				0, 0, 4,
+				1, 0, 5,
+				0, 1, 6,
+			}),
+		},
+		{
+			enc:    Dummy,
+			labels: []string{"colour=red", "count"},
+			want: mat.NewDense(4, 2, []float64{
+				1, 3,
+				0, 4,
+				0, 5,
+				1, 6,
+			}),
+		},
+		{
+			enc:    Ordinal,
+			labels: []string{"colour", "count"},
+			want: mat.NewDense(4, 2, []float64{
+				1, 3,
+				nan, 4,
+				0, 5,
+				1, 6,
+			}),
+		},
+	} {
+		got, labels, err := tab.Design(test.enc, "colour", "count")
+		if err != nil {
+			t.Errorf("unexpected error for encoding %d: %v", test.enc, err)
+			continue
+		}
+		if !reflect.DeepEqual(labels, test.labels) {
+			t.Errorf("unexpected labels for encoding %d: got:%q want:%q", test.enc, labels, test.labels)
+		}
+		if !equalNaN(got, test.want) {
+			t.Errorf("unexpected design for encoding %d:\ngot: %v\nwant:%v", test.enc, mat.Formatted(got), mat.Formatted(test.want))
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tab, err := ReadTable(strings.NewReader(tableData), tableOpt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = tab.Column("colour").Reorder([]string{"red", "blue"})
+	if err != nil {
+		t.Fatalf("unexpected error reordering: %v", err)
+	}
+	y, classes, err := tab.Labels("colour")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"red", "blue"}; !reflect.DeepEqual(classes, want) {
+		t.Errorf("unexpected classes: got:%q want:%q", classes, want)
+	}
+	if want := mat.NewVecDense(4, []float64{0, math.NaN(), 1, 0}); !equalNaN(y, want) {
+		t.Errorf("unexpected labels: got:%v want:%v", y.RawVector().Data, want.RawVector().Data)
+	}
+	_, _, err = tab.Labels("count")
+	if err == nil {
+		t.Error("expected error for numeric column")
+	}
+}
+
+func TestReadCensus(t *testing.T) {
+	tab, err := ReadTableFile(filepath.FromSlash("../DATA/census1994.csv"), Options{
+		Comma:   ',',
+		Header:  true,
+		Missing: []string{"?", "<undefined>"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tab.Rows() != 32561 {
+		t.Errorf("unexpected number of rows: got:%d want:32561", tab.Rows())
+	}
+	for _, want := range []struct {
+		name    string
+		levels  int
+		missing int
+	}{
+		{name: "workClass", levels: 8, missing: 1836},
+		{name: "education", levels: 16},
+		{name: "marital_status", levels: 7},
+		{name: "occupation", levels: 14, missing: 1843},
+		{name: "native_country", levels: 41, missing: 583},
+		{name: "salary", levels: 2},
+	} {
+		c := tab.Column(want.name)
+		if c == nil || c.Kind != Categorical {
+			t.Errorf("expected categorical column %q", want.name)
+			continue
+		}
+		if len(c.Levels) != want.levels {
+			t.Errorf("unexpected number of levels for %q: got:%d want:%d", want.name, len(c.Levels), want.levels)
+		}
+		var missing int
+		for i := 0; i < c.Len(); i++ {
+			if c.IsMissing(i) {
+				missing++
+			}
+		}
+		if missing != want.missing {
+			t.Errorf("unexpected number of missing values for %q: got:%d want:%d", want.name, missing, want.missing)
+		}
+	}
+
+	clean, err := tab.DropMissing()
+	if err != nil {
+		t.Fatalf("unexpected error dropping missing rows: %v", err)
+	}
+	if clean.Rows() != 30162 {
+		t.Errorf("unexpected number of complete rows: got:%d want:30162", clean.Rows())
+	}
+	y, classes, err := clean.Labels("salary")
+	if err != nil {
+		t.Fatalf("unexpected error getting labels: %v", err)
+	}
+	counts := make([]int, len(classes))
+	for _, v := range y.RawVector().Data {
+		counts[int(v)]++
+	}
+	if want := []int{22654, 7508}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("unexpected salary class counts for %q: got:%d want:%d", classes, counts, want)
+	}
+
+	var predictors []string
+	for _, c := range clean.Columns {
+		if c.Name != "salary" {
+			predictors = append(predictors, c.Name)
+		}
+	}
+	for _, test := range []struct {
+		enc  Encoding
+		cols int
+	}{
+		{enc: OneHot, cols: 105},
+		{enc: Dummy, cols: 97},
+		{enc: Ordinal, cols: 14},
+	} {
+		x, labels, err := clean.Design(test.enc, predictors...)
+		if err != nil {
+			t.Errorf("unexpected error for encoding %d: %v", test.enc, err)
+			continue
+		}
+		r, c := x.Dims()
+		if r != 30162 || c != test.cols || len(labels) != test.cols {
+			t.Errorf("unexpected design dimensions for encoding %d: got:%d×%d with %d labels want:30162×%d", test.enc, r, c, len(labels), test.cols)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package golden holds regression tests for the numerical results of the
+// demo programs.
+//
+// The demos are main packages and so cannot be imported. Instead the tests
+// build and run each demo with the default random seed and check the values
+// it prints. Where the MATLAB and Python companions to the book report a
+// value it is used, otherwise the expected value is a regression value
+// taken from the output of the demo and is labelled as such.
+package golden
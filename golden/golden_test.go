@@ -0,0 +1,204 @@
+package golden
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kortschak/databook_gonum/seed"
+)
+
+// run builds and runs the demo at path, relative to the repository root,
+// with the default seed and returns its standard output. The demo is run
+// in a temporary chapter directory with the DATA directory as a sibling,
+// as it is by the rundemos command.
+func run(t *testing.T, path string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping demo run in short mode")
+	}
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "golden")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	err = os.Symlink(filepath.Join(root, "DATA"), filepath.Join(dir, "DATA"))
+	if err != nil {
+		t.Fatalf("unexpected error linking data: %v", err)
+	}
+	chapter := filepath.Join(dir, filepath.Dir(filepath.FromSlash(path)))
+	err = os.Mkdir(chapter, 0o775)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exe := filepath.Join(dir, "demo")
+	build := exec.Command("go", "build", "-o", exe, "./"+path)
+	build.Dir = root
+	msg, err := build.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build %s: %v\n%s", path, err, msg)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(exe)
+	cmd.Dir = chapter
+	cmd.Env = append(os.Environ(), seed.Env+"="+strconv.Itoa(seed.Default))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		t.Fatalf("failed to run %s: %v\n%s", path, err, stderr.Bytes())
+	}
+	return stdout.String()
+}
+
+// values returns the numbers in the first capture group of the first
+// match of the pattern in out.
+func values(t *testing.T, out, pattern string) []float64 {
+	t.Helper()
+	m := regexp.MustCompile(pattern).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no match for %q in output:\n%s", pattern, out)
+	}
+	var v []float64
+	for _, f := range strings.Fields(m[1]) {
+		x, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", f, err)
+		}
+		v = append(v, x)
+	}
+	return v
+}
+
+func TestCement(t *testing.T) {
+	out := run(t, "CH01/CH01_SEC04_2_Cement.go")
+
+	// Coefficients reported by MATLAB's regress(heat, ingredients).
+	want := []float64{2.1930, 1.1533, 0.7585, 0.4863}
+	got := values(t, out, `\[\s*([^\]]+)\]`)
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of coefficients: got:%d want:%d", len(got), len(want))
+	}
+	for i, w := range want {
+		if math.Abs(got[i]-w) > 5e-5 {
+			t.Errorf("unexpected coefficient %d: got:%.4f want:%.4f", i, got[i], w)
+		}
+	}
+	if rank := values(t, out, `rank = (\d+)`)[0]; rank != 4 {
+		t.Errorf("unexpected rank: got:%v want:4", rank)
+	}
+}
+
+func TestHousing(t *testing.T) {
+	out := run(t, "CH01/CH01_SEC04_3_Housing.go")
+
+	// Coefficients reported by the Python companion's least squares
+	// solution, with the intercept moved to the end to match the layout
+	// given by preprocess.Intercept.
+	want := []float64{
+		-0.1080, 0.0464, 0.0206, 2.6867, -17.7666, 3.8099, 0.0007,
+		-1.4756, 0.3060, -0.0123, -0.9527, 0.0093, -0.5248,
+		36.4595,
+	}
+	// The demo prints the attribute coefficients followed
+	// by the intercept.
+	got := values(t, out, `(?m)^\[([^\]]+)\] \S+$`)
+	got = append(got, values(t, out, `(?m)^\[[^\]]+\] (\S+)$`)...)
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of coefficients: got:%d want:%d", len(got), len(want))
+	}
+	for i, w := range want {
+		if math.Abs(got[i]-w) > 5e-5 {
+			t.Errorf("unexpected coefficient %d: got:%.4f want:%.4f", i, got[i], w)
+		}
+	}
+	if r2 := values(t, out, `R² = (\S+)`)[0]; math.Abs(r2-0.7406) > 5e-5 {
+		t.Errorf("unexpected R²: got:%.4f want:0.7406", r2)
+	}
+}
+
+func TestDenoise(t *testing.T) {
+	out := run(t, "CH02/CH02_SEC02_2_Denoise.go")
+
+	// The signal is the sum of 50 Hz and 120 Hz sinusoids, and these
+	// are the only frequencies above the periodogram threshold, as in
+	// the MATLAB and Python companions.
+	peaks := values(t, out, `Periodogram: .* peaks at \[([^\]]*)\] Hz`)
+	if len(peaks) != 2 || peaks[0] != 50 || peaks[1] != 120 {
+		t.Errorf("unexpected periodogram peaks: got:%v Hz want:[50 120] Hz", peaks)
+	}
+
+	// The companions do not report the error of the filtered signal,
+	// so these are regression values from the output of this demo.
+	for _, test := range []struct {
+		name string
+		want float64
+	}{
+		{name: "noisy", want: 2.4890},
+		{name: "PSD threshold", want: 0.1710},
+	} {
+		got := values(t, out, regexp.QuoteMeta(test.name)+`: RMS error = (\S+)`)[0]
+		if math.Abs(got-test.want) > 5e-5 {
+			t.Errorf("unexpected %s RMS error: got:%.4f want:%.4f", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSpectralDerivative(t *testing.T) {
+	out := run(t, "CH02/CH02_SEC02_3_SpectralDerivative.go")
+
+	// The companions only plot the derivatives, so these are regression
+	// values from the output of this demo. They are printed once for the
+	// complex input FFT and once for spectral.Deriv, and both must agree.
+	// The finite difference error is first order in the grid spacing and
+	// the spectral derivative error is limited by the periodic extension
+	// of f, which is not smooth at the ends of the domain.
+	for _, test := range []struct {
+		name string
+		want float64
+		tol  float64
+	}{
+		{name: "finite difference", want: 0.12576, tol: 5e-6},
+		{name: "FFT derivative", want: 3.2252e-5, tol: 5e-9},
+	} {
+		m := regexp.MustCompile(regexp.QuoteMeta(test.name)+`: maximum error = (\S+)`).FindAllStringSubmatch(out, -1)
+		if len(m) != 2 {
+			t.Errorf("unexpected number of %s results: got:%d want:2", test.name, len(m))
+			continue
+		}
+		for i, s := range m {
+			got, err := strconv.ParseFloat(s[1], 64)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %q: %v", s[1], err)
+			}
+			if math.Abs(got-test.want) > test.tol {
+				t.Errorf("unexpected %s maximum error in block %d: got:%.5g want:%.5g", test.name, i+1, got, test.want)
+			}
+		}
+	}
+
+	// A smooth periodic function converges spectrally: doubling the
+	// resolution from 16 to 32 points gains at least six digits.
+	for order := 1; order <= 3; order++ {
+		coarse := values(t, out, `n=16 order=`+strconv.Itoa(order)+`: maximum error = (\S+)`)[0]
+		fine := values(t, out, `n=32 order=`+strconv.Itoa(order)+`: maximum error = (\S+)`)[0]
+		if fine > 1e-6*coarse {
+			t.Errorf("unexpected convergence for order %d: n=16 error=%g n=32 error=%g", order, coarse, fine)
+		}
+	}
+	if got := values(t, out, `dealias=true: maximum \|d\(u²\)/dx\| = (\S+)`)[0]; got > 1e-12 {
+		t.Errorf("unexpected dealiased derivative: got:%g want:0", got)
+	}
+}
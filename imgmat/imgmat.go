@@ -0,0 +1,205 @@
+// Package imgmat provides conversions between images and matrices.
+//
+// Matrices hold pixel intensities in the range [0, 255] with rows
+// corresponding to image rows and columns to image columns, so the
+// pixel at image.Point{X: x, Y: y} of an image with bounds b is held
+// at row y-b.Min.Y and column x-b.Min.X.
+package imgmat
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Gray returns the luminance of img as a matrix.
+func Gray(img image.Image) *mat.Dense {
+	b := img.Bounds()
+	m := mat.NewDense(b.Dy(), b.Dx(), nil)
+	for i := 0; i < b.Dy(); i++ {
+		for j := 0; j < b.Dx(); j++ {
+			m.Set(i, j, float64(color.GrayModel.Convert(img.At(b.Min.X+j, b.Min.Y+i)).(color.Gray).Y))
+		}
+	}
+	return m
+}
+
+// Channels returns the non-alpha-premultiplied red, green, blue and alpha
+// channels of img as matrices.
+func Channels(img image.Image) (r, g, b, a *mat.Dense) {
+	bounds := img.Bounds()
+	rows, cols := bounds.Dy(), bounds.Dx()
+	r = mat.NewDense(rows, cols, nil)
+	g = mat.NewDense(rows, cols, nil)
+	b = mat.NewDense(rows, cols, nil)
+	a = mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+j, bounds.Min.Y+i)).(color.NRGBA)
+			r.Set(i, j, float64(c.R))
+			g.Set(i, j, float64(c.G))
+			b.Set(i, j, float64(c.B))
+			a.Set(i, j, float64(c.A))
+		}
+	}
+	return r, g, b, a
+}
+
+// RGB returns the red, green and blue channels of img as matrices,
+// ignoring alpha.
+func RGB(img image.Image) [3]*mat.Dense {
+	r, g, b, _ := Channels(img)
+	return [3]*mat.Dense{r, g, b}
+}
+
+// Scaling specifies how matrix values are mapped to pixel intensities.
+// Scaled values are rounded to the nearest intensity and NaN values are
+// mapped to zero.
+type Scaling int
+
+const (
+	// Clamp clamps values to the range [0, 255].
+	Clamp Scaling = iota
+
+	// Normalize linearly maps values from the range of the
+	// data to [0, 255]. For multi-channel images the range
+	// is taken over all channels.
+	Normalize
+)
+
+// GrayImage returns an image with luminance given by m, scaled according
+// to s. The returned image has its origin at (0, 0).
+func GrayImage(m mat.Matrix, s Scaling) *image.Gray {
+	rows, cols := m.Dims()
+	scale := scaler(s, m)
+	img := image.NewGray(image.Rect(0, 0, cols, rows))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			img.SetGray(j, i, color.Gray{Y: scale(m.At(i, j))})
+		}
+	}
+	return img
+}
+
+// RGBImage returns an opaque image with red, green and blue channels given
+// by r, g and b, scaled according to s. The matrices must have the same
+// dimensions. The returned image has its origin at (0, 0).
+func RGBImage(r, g, b mat.Matrix, s Scaling) (*image.RGBA, error) {
+	rows, cols := r.Dims()
+	for _, c := range []mat.Matrix{g, b} {
+		cr, cc := c.Dims()
+		if cr != rows || cc != cols {
+			return nil, fmt.Errorf("imgmat: channel dimension mismatch: %d×%d != %d×%d", cr, cc, rows, cols)
+		}
+	}
+	scale := scaler(s, r, g, b)
+	img := image.NewRGBA(image.Rect(0, 0, cols, rows))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			img.SetRGBA(j, i, color.RGBA{
+				R: scale(r.At(i, j)),
+				G: scale(g.At(i, j)),
+				B: scale(b.At(i, j)),
+				A: 255,
+			})
+		}
+	}
+	return img, nil
+}
+
+// Reshape returns the rows×cols matrix holding the elements of v in
+// column-major order, as done by MATLAB's reshape. It recovers images
+// stored as the columns of a data matrix. Reshape panics if v does not
+// have rows*cols elements.
+func Reshape(v mat.Vector, rows, cols int) *mat.Dense {
+	if v.Len() != rows*cols {
+		panic(fmt.Sprintf("imgmat: cannot reshape %d elements to %d×%d", v.Len(), rows, cols))
+	}
+	m := mat.NewDense(rows, cols, nil)
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			m.Set(i, j, v.AtVec(j*rows+i))
+		}
+	}
+	return m
+}
+
+// Grid returns an image with imgs tiled in row-major order into a grid
+// with the given number of columns. Each cell is the size of the largest
+// image and cells are separated by pad pixels of the background color bg.
+// The returned image has its origin at (0, 0).
+func Grid(imgs []image.Image, cols, pad int, bg color.Color) *image.RGBA {
+	if cols <= 0 {
+		panic("imgmat: invalid column count")
+	}
+	var cell image.Point
+	for _, img := range imgs {
+		size := img.Bounds().Size()
+		if size.X > cell.X {
+			cell.X = size.X
+		}
+		if size.Y > cell.Y {
+			cell.Y = size.Y
+		}
+	}
+	rows := (len(imgs) + cols - 1) / cols
+	if len(imgs) < cols {
+		cols = len(imgs)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, cols*cell.X+(cols+1)*pad, rows*cell.Y+(rows+1)*pad))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	for k, img := range imgs {
+		min := image.Point{
+			X: pad + (k%cols)*(cell.X+pad),
+			Y: pad + (k/cols)*(cell.Y+pad),
+		}
+		b := img.Bounds()
+		draw.Draw(dst, image.Rectangle{Min: min, Max: min.Add(b.Size())}, img, b.Min, draw.Src)
+	}
+	return dst
+}
+
+// scaler returns a function mapping values in the given matrices to
+// 8-bit intensities according to s.
+func scaler(s Scaling, m ...mat.Matrix) func(float64) uint8 {
+	switch s {
+	case Clamp:
+		return clamp
+	case Normalize:
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, c := range m {
+			rows, cols := c.Dims()
+			for i := 0; i < rows; i++ {
+				for j := 0; j < cols; j++ {
+					v := c.At(i, j)
+					if math.IsNaN(v) {
+						continue
+					}
+					min = math.Min(min, v)
+					max = math.Max(max, v)
+				}
+			}
+		}
+		if max <= min {
+			// All values are equal or NaN.
+			return func(float64) uint8 { return 0 }
+		}
+		scale := 255 / (max - min)
+		return func(v float64) uint8 { return clamp((v - min) * scale) }
+	default:
+		panic("imgmat: invalid scaling")
+	}
+}
+
+// clamp returns v rounded to the nearest integer in [0, 255],
+// or zero if v is NaN.
+func clamp(v float64) uint8 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return uint8(math.Round(math.Min(math.Max(0, v), 255)))
+}
@@ -0,0 +1,164 @@
+package imgmat
+
+import (
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var testImages = []string{"dog.jpg", "jupiter.jpg", "mustache.jpg"}
+
+func decode(t *testing.T, name string) image.Image {
+	t.Helper()
+	f, err := os.Open(filepath.Join("..", "DATA", name))
+	if err != nil {
+		t.Fatalf("unexpected error opening %s: %v", name, err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatalf("unexpected error decoding %s: %v", name, err)
+	}
+	return img
+}
+
+type subImager interface {
+	SubImage(image.Rectangle) image.Image
+}
+
+func TestGray(t *testing.T) {
+	for _, name := range testImages {
+		img := decode(t, name)
+		b := img.Bounds()
+		full := Gray(img)
+		if r, c := full.Dims(); r != b.Dy() || c != b.Dx() {
+			t.Errorf("unexpected dimensions for %s: got:%d×%d want:%d×%d", name, r, c, b.Dy(), b.Dx())
+		}
+
+		// Use a non-square sub-image with a non-zero origin
+		// to catch transposed indexing.
+		rect := image.Rect(b.Dx()/5, b.Dy()/3, b.Dx()/2, b.Dy()-1)
+		sub := img.(subImager).SubImage(rect)
+		got := Gray(sub)
+		want := full.Slice(rect.Min.Y, rect.Max.Y, rect.Min.X, rect.Max.X)
+		if !mat.Equal(got, want) {
+			t.Errorf("sub-image luminance mismatch for %s", name)
+		}
+
+		back := GrayImage(got, Clamp)
+		if back.Bounds() != image.Rect(0, 0, rect.Dx(), rect.Dy()) {
+			t.Errorf("unexpected bounds for %s: got:%v want origin at zero with size %v", name, back.Bounds(), rect.Size())
+		}
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				want := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				got := back.GrayAt(x-rect.Min.X, y-rect.Min.Y)
+				if got != want {
+					t.Fatalf("unexpected round trip pixel for %s at (%d,%d): got:%v want:%v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRGB(t *testing.T) {
+	for _, name := range testImages {
+		img := decode(t, name)
+		b := img.Bounds()
+		rect := image.Rect(b.Dx()/4, b.Dy()/4, b.Dx()-b.Dx()/3, b.Dy()-2)
+		sub := img.(subImager).SubImage(rect)
+		c := RGB(sub)
+		back, err := RGBImage(c[0], c[1], c[2], Clamp)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				want := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				got := back.RGBAAt(x-rect.Min.X, y-rect.Min.Y)
+				if got.R != want.R || got.G != want.G || got.B != want.B {
+					t.Fatalf("unexpected round trip pixel for %s at (%d,%d): got:%v want:%v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestScaling(t *testing.T) {
+	m := mat.NewDense(2, 4, []float64{
+		-10, 0, 100, 99.4,
+		127.5, 255, 300, math.NaN(),
+	})
+	for _, test := range []struct {
+		s    Scaling
+		want []uint8
+	}{
+		{s: Clamp, want: []uint8{0, 0, 100, 99, 128, 255, 255, 0}},
+		{s: Normalize, want: []uint8{0, 8, 90, 90, 113, 218, 255, 0}},
+	} {
+		got := GrayImage(m, test.s)
+		for i, w := range test.want {
+			if got.Pix[i] != w {
+				t.Errorf("unexpected pixel %d for scaling %d: got:%d want:%d", i, test.s, got.Pix[i], w)
+			}
+		}
+	}
+
+	nan := mat.NewDense(1, 2, []float64{math.NaN(), math.NaN()})
+	if got := GrayImage(nan, Normalize); got.Pix[0] != 0 || got.Pix[1] != 0 {
+		t.Errorf("unexpected pixels for all NaN data: got:%v want:[0 0]", got.Pix)
+	}
+
+	_, err := RGBImage(m, m, mat.NewDense(3, 2, nil), Clamp)
+	if err == nil {
+		t.Error("expected error for mismatched channel dimensions")
+	}
+}
+
+func TestReshape(t *testing.T) {
+	v := mat.NewVecDense(6, []float64{0, 1, 2, 3, 4, 5})
+	got := Reshape(v, 2, 3)
+	want := mat.NewDense(2, 3, []float64{
+		0, 2, 4,
+		1, 3, 5,
+	})
+	if !mat.Equal(got, want) {
+		t.Errorf("unexpected reshape:\ngot:\n%v\nwant:\n%v", mat.Formatted(got), mat.Formatted(want))
+	}
+}
+
+func TestGrid(t *testing.T) {
+	white := color.Gray{Y: 255}
+	var imgs []image.Image
+	for k := 0; k < 5; k++ {
+		img := image.NewGray(image.Rect(10, 20, 13, 22))
+		for i := range img.Pix {
+			img.Pix[i] = uint8(k)
+		}
+		imgs = append(imgs, img)
+	}
+	const pad = 1
+	got := Grid(imgs, 3, pad, white)
+	if size := got.Bounds().Size(); size != image.Pt(3*3+4*pad, 2*2+3*pad) {
+		t.Fatalf("unexpected grid size: got:%v want:%v", size, image.Pt(13, 7))
+	}
+	for k := range imgs {
+		x := pad + (k%3)*(3+pad)
+		y := pad + (k/3)*(2+pad)
+		if c := color.GrayModel.Convert(got.At(x, y)).(color.Gray); c.Y != uint8(k) {
+			t.Errorf("unexpected pixel value for image %d: got:%d want:%d", k, c.Y, k)
+		}
+	}
+	if c := color.GrayModel.Convert(got.At(0, 0)).(color.Gray); c != white {
+		t.Errorf("unexpected background: got:%v want:%v", c, white)
+	}
+	if c := color.GrayModel.Convert(got.At(got.Bounds().Max.X-2, got.Bounds().Max.Y-2)).(color.Gray); c != white {
+		t.Errorf("unexpected empty cell: got:%v want:%v", c, white)
+	}
+}
@@ -0,0 +1,102 @@
+package lowrank
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ChannelMode specifies how the channels of a color image are factorized.
+type ChannelMode int
+
+const (
+	// PerChannel factorizes each channel independently.
+	PerChannel ChannelMode = iota
+
+	// Stacked factorizes the channels stacked vertically
+	// into a single matrix so that they share right
+	// singular vectors.
+	Stacked
+)
+
+// Color holds low-rank factorizations of the channels of a color image.
+type Color struct {
+	mode       ChannelMode
+	rows, cols int
+	svds       []*SVD
+}
+
+// NewColor returns the factorization of the given image channels according
+// to mode. All channels must have the same dimensions.
+func NewColor(channels [3]*mat.Dense, mode ChannelMode) (*Color, error) {
+	rows, cols := channels[0].Dims()
+	for i, c := range channels[1:] {
+		r, k := c.Dims()
+		if r != rows || k != cols {
+			return nil, fmt.Errorf("lowrank: channel %d dimension mismatch: %d×%d != %d×%d", i+1, r, k, rows, cols)
+		}
+	}
+
+	c := &Color{mode: mode, rows: rows, cols: cols}
+	switch mode {
+	case PerChannel:
+		for _, ch := range channels {
+			svd, err := Factorize(ch)
+			if err != nil {
+				return nil, err
+			}
+			c.svds = append(c.svds, svd)
+		}
+	case Stacked:
+		stack := mat.NewDense(len(channels)*rows, cols, nil)
+		for i, ch := range channels {
+			stack.Slice(i*rows, (i+1)*rows, 0, cols).(*mat.Dense).Copy(ch)
+		}
+		svd, err := Factorize(stack)
+		if err != nil {
+			return nil, err
+		}
+		c.svds = []*SVD{svd}
+	default:
+		panic("lowrank: invalid channel mode")
+	}
+	return c, nil
+}
+
+// Approx returns the rank r approximations of the image channels.
+func (c *Color) Approx(r int) [3]*mat.Dense {
+	var channels [3]*mat.Dense
+	switch c.mode {
+	case PerChannel:
+		for i, svd := range c.svds {
+			channels[i] = &mat.Dense{}
+			svd.Approx(channels[i], r)
+		}
+	case Stacked:
+		var stack mat.Dense
+		c.svds[0].Approx(&stack, r)
+		for i := range channels {
+			channels[i] = mat.DenseCopyOf(stack.Slice(i*c.rows, (i+1)*c.rows, 0, c.cols))
+		}
+	}
+	return channels
+}
+
+// CompressionRatio returns the ratio of the number of values in the image
+// channels to the number of values needed to store their rank r
+// approximations.
+func (c *Color) CompressionRatio(r int) float64 {
+	n := len(c.svds)
+	rows, _ := c.svds[0].U.Dims()
+	return float64(3*c.rows*c.cols) / float64(n*r*(rows+c.cols+1))
+}
+
+// RelativeError returns the relative Frobenius norm error of the rank r
+// approximation over all channels.
+func (c *Color) RelativeError(r int) float64 {
+	sigmas := make([][]float64, len(c.svds))
+	for i, svd := range c.svds {
+		sigmas[i] = svd.Sigma
+	}
+	return relativeError(r, sigmas...)
+}
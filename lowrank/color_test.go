@@ -0,0 +1,76 @@
+package lowrank
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestColor(t *testing.T) {
+	const (
+		rows = 20
+		cols = 15
+		r    = 4
+	)
+	rnd := rand.New(rand.NewSource(1))
+	var channels [3]*mat.Dense
+	for i := range channels {
+		channels[i] = randRank(rows, cols, cols, rnd)
+	}
+
+	for _, test := range []struct {
+		mode  ChannelMode
+		svds  int
+		urows int
+		ratio float64
+	}{
+		{mode: PerChannel, svds: 3, urows: rows, ratio: 3 * rows * cols / float64(3*r*(rows+cols+1))},
+		{mode: Stacked, svds: 1, urows: 3 * rows, ratio: 3 * rows * cols / float64(r*(3*rows+cols+1))},
+	} {
+		c, err := NewColor(channels, test.mode)
+		if err != nil {
+			t.Fatalf("unexpected error for mode %d: %v", test.mode, err)
+		}
+		if len(c.svds) != test.svds {
+			t.Errorf("unexpected number of factorizations for mode %d: got:%d want:%d", test.mode, len(c.svds), test.svds)
+		}
+		for _, svd := range c.svds {
+			if ur, uc := svd.U.Dims(); ur != test.urows || uc != cols {
+				t.Errorf("unexpected U dimensions for mode %d: got:%d×%d want:%d×%d", test.mode, ur, uc, test.urows, cols)
+			}
+		}
+		if got := c.CompressionRatio(r); math.Abs(got-test.ratio) > 1e-14 {
+			t.Errorf("unexpected compression ratio for mode %d: got:%v want:%v", test.mode, got, test.ratio)
+		}
+
+		approx := c.Approx(r)
+		var num, den float64
+		for i, a := range approx {
+			if ar, ac := a.Dims(); ar != rows || ac != cols {
+				t.Errorf("unexpected channel %d dimensions for mode %d: got:%d×%d want:%d×%d", i, test.mode, ar, ac, rows, cols)
+				continue
+			}
+			var diff mat.Dense
+			diff.Sub(channels[i], a)
+			num += math.Pow(mat.Norm(&diff, 2), 2)
+			den += math.Pow(mat.Norm(channels[i], 2), 2)
+		}
+		if got, want := c.RelativeError(r), math.Sqrt(num/den); math.Abs(got-want) > 1e-12 {
+			t.Errorf("unexpected relative error for mode %d: got:%v want:%v", test.mode, got, want)
+		}
+
+		for i, a := range c.Approx(cols) {
+			if !mat.EqualApprox(a, channels[i], 1e-10) {
+				t.Errorf("full rank channel %d not reconstructed for mode %d", i, test.mode)
+			}
+		}
+	}
+
+	channels[2] = mat.NewDense(rows, cols+1, nil)
+	_, err := NewColor(channels, PerChannel)
+	if err == nil {
+		t.Error("expected error for mismatched channel dimensions")
+	}
+}
@@ -0,0 +1,89 @@
+// Package lowrank provides low-rank matrix approximations based on the
+// singular value decomposition.
+package lowrank
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrFactorize is returned when a singular value decomposition fails.
+var ErrFactorize = errors.New("lowrank: failed to factorize matrix")
+
+// SVD is a thin singular value decomposition, A = U Σ Vᵀ.
+type SVD struct {
+	// U and V hold the left and right singular vectors
+	// in their columns.
+	U, V *mat.Dense
+
+	// Sigma holds the singular values in descending order.
+	Sigma []float64
+}
+
+// Factorize returns the thin singular value decomposition of a.
+func Factorize(a mat.Matrix) (*SVD, error) {
+	var svd mat.SVD
+	ok := svd.Factorize(a, mat.SVDThin)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	return &SVD{U: &u, V: &v, Sigma: svd.Values(nil)}, nil
+}
+
+// Approx places the rank r approximation of the factorized matrix,
+// U[:, :r] Σ[:r, :r] V[:, :r]ᵀ, into dst. If r is greater than the
+// number of singular values, all of them are used.
+func (s *SVD) Approx(dst *mat.Dense, r int) {
+	if r > len(s.Sigma) {
+		r = len(s.Sigma)
+	}
+	rows, _ := s.U.Dims()
+	cols, _ := s.V.Dims()
+	dst.Reset()
+	if r <= 0 {
+		dst.ReuseAs(rows, cols)
+		return
+	}
+	var us mat.Dense
+	us.Mul(s.U.Slice(0, rows, 0, r), mat.NewDiagDense(r, s.Sigma[:r]))
+	dst.Mul(&us, s.V.Slice(0, cols, 0, r).T())
+}
+
+// RelativeError returns the relative Frobenius norm error of the rank r
+// approximation of the factorized matrix, ‖A - Aᵣ‖_F / ‖A‖_F, computed
+// from the discarded singular values.
+func (s *SVD) RelativeError(r int) float64 {
+	return relativeError(r, s.Sigma)
+}
+
+// relativeError returns the relative Frobenius norm error of the rank r
+// truncation of all the given singular value sets taken together.
+func relativeError(r int, sigmas ...[]float64) float64 {
+	var tail, total float64
+	for _, sigma := range sigmas {
+		for i, v := range sigma {
+			v *= v
+			total += v
+			if i >= r {
+				tail += v
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(tail / total)
+}
+
+// CompressionRatio returns the ratio of the number of values in a
+// rows×cols matrix to the number of values needed to store its rank r
+// approximation as r left and right singular vectors and r singular
+// values.
+func CompressionRatio(rows, cols, r int) float64 {
+	return float64(rows*cols) / float64(r*(rows+cols+1))
+}
@@ -0,0 +1,78 @@
+package lowrank
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// randRank returns a random rows×cols matrix of the given rank.
+func randRank(rows, cols, rank int, rnd *rand.Rand) *mat.Dense {
+	var u, v, a mat.Dense
+	u.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, mat.NewDense(rows, rank, nil))
+	v.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, mat.NewDense(rank, cols, nil))
+	a.Mul(&u, &v)
+	return &a
+}
+
+func TestSVDExactRank(t *testing.T) {
+	const (
+		rows = 40
+		cols = 30
+		rank = 5
+	)
+	rnd := rand.New(rand.NewSource(1))
+	a := randRank(rows, cols, rank, rnd)
+	svd, err := Factorize(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(svd.Sigma) != cols {
+		t.Fatalf("unexpected number of singular values: got:%d want:%d", len(svd.Sigma), cols)
+	}
+	for i, s := range svd.Sigma[rank:] {
+		if s > 1e-12*svd.Sigma[0] {
+			t.Errorf("unexpected non-zero singular value %d: %v", i+rank, s)
+		}
+	}
+
+	var approx mat.Dense
+	svd.Approx(&approx, rank)
+	if !mat.EqualApprox(&approx, a, 1e-10) {
+		t.Error("rank approximation does not reconstruct exact rank matrix")
+	}
+	svd.Approx(&approx, 2*cols)
+	if !mat.EqualApprox(&approx, a, 1e-10) {
+		t.Error("full approximation does not reconstruct matrix")
+	}
+	svd.Approx(&approx, 0)
+	if r, c := approx.Dims(); r != rows || c != cols || mat.Norm(&approx, math.Inf(1)) != 0 {
+		t.Errorf("unexpected rank zero approximation: %d×%d with norm %v", r, c, mat.Norm(&approx, math.Inf(1)))
+	}
+
+	for _, r := range []int{0, 1, 2, rank} {
+		svd.Approx(&approx, r)
+		var diff mat.Dense
+		diff.Sub(a, &approx)
+		want := mat.Norm(&diff, 2) / mat.Norm(a, 2)
+		if got := svd.RelativeError(r); math.Abs(got-want) > 1e-10 {
+			t.Errorf("unexpected relative error for rank %d: got:%v want:%v", r, got, want)
+		}
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	for _, test := range []struct {
+		rows, cols, r int
+		want          float64
+	}{
+		{rows: 100, cols: 50, r: 10, want: 5000.0 / 1510},
+		{rows: 10, cols: 10, r: 10, want: 100.0 / 210},
+	} {
+		if got := CompressionRatio(test.rows, test.cols, test.r); got != test.want {
+			t.Errorf("unexpected compression ratio for %d×%d rank %d: got:%v want:%v", test.rows, test.cols, test.r, got, test.want)
+		}
+	}
+}
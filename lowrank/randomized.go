@@ -0,0 +1,103 @@
+package lowrank
+
+import (
+	"fmt"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Randomized returns a rank r approximation of the thin singular value
+// decomposition of a computed using the randomized range finder of Halko,
+// Martinsson and Tropp, "Finding structure with randomness: Probabilistic
+// algorithms for constructing approximate matrix decompositions", SIAM
+// Review 53(2), 2011.
+//
+// The range of a is sampled with r+oversample Gaussian test vectors drawn
+// from rnd, and power subspace iterations are performed to sharpen the
+// approximation for matrices with slowly decaying singular values. A small
+// oversampling, such as 10, and one or two power iterations are typically
+// sufficient. If rnd is nil, a source with a fixed seed is used so that
+// the result is reproducible.
+func Randomized(a mat.Matrix, r, oversample, power int, rnd *rand.Rand) (*SVD, error) {
+	rows, cols := a.Dims()
+	if r <= 0 {
+		return nil, fmt.Errorf("lowrank: invalid rank: %d", r)
+	}
+	if oversample < 0 || power < 0 {
+		return nil, fmt.Errorf("lowrank: invalid oversampling or power iterations: %d, %d", oversample, power)
+	}
+	k := r + oversample
+	if n := minInt(rows, cols); k > n {
+		k = n
+	}
+	if r > k {
+		r = k
+	}
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(1))
+	}
+
+	omega := mat.NewDense(cols, k, nil)
+	data := omega.RawMatrix().Data
+	for i := range data {
+		data[i] = rnd.NormFloat64()
+	}
+
+	// Sample the range of a and refine it with power iterations,
+	// orthonormalizing between each application of a and aᵀ to
+	// avoid loss of precision.
+	var y, z mat.Dense
+	y.Mul(a, omega)
+	for i := 0; i < power; i++ {
+		q, err := orthonormalize(&y)
+		if err != nil {
+			return nil, err
+		}
+		z.Mul(a.T(), q)
+		q, err = orthonormalize(&z)
+		if err != nil {
+			return nil, err
+		}
+		y.Mul(a, q)
+	}
+	q, err := orthonormalize(&y)
+	if err != nil {
+		return nil, err
+	}
+
+	// Factorize the projection of a onto the sampled range.
+	var b mat.Dense
+	b.Mul(q.T(), a)
+	small, err := Factorize(&b)
+	if err != nil {
+		return nil, err
+	}
+	var u mat.Dense
+	u.Mul(q, small.U.Slice(0, k, 0, r))
+	return &SVD{
+		U:     &u,
+		V:     mat.DenseCopyOf(small.V.Slice(0, cols, 0, r)),
+		Sigma: small.Sigma[:r],
+	}, nil
+}
+
+// orthonormalize returns a matrix with orthonormal columns spanning the
+// column space of a.
+func orthonormalize(a *mat.Dense) (*mat.Dense, error) {
+	var svd mat.SVD
+	ok := svd.Factorize(a, mat.SVDThinU)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	var q mat.Dense
+	svd.UTo(&q)
+	return &q, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package lowrank
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/kortschak/databook_gonum/imgmat"
+)
+
+func dog(tb testing.TB) *mat.Dense {
+	tb.Helper()
+	f, err := os.Open(filepath.FromSlash("../DATA/dog.jpg"))
+	if err != nil {
+		tb.Fatalf("unexpected error opening image: %v", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		tb.Fatalf("unexpected error decoding image: %v", err)
+	}
+	return imgmat.Gray(img)
+}
+
+func TestRandomized(t *testing.T) {
+	const r = 10
+	rnd := rand.New(rand.NewSource(1))
+
+	// Construct a matrix with known rank r plus a little noise.
+	a := mat.NewDense(300, 200, nil)
+	var u, v mat.Dense
+	u.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, mat.NewDense(300, r, nil))
+	v.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, mat.NewDense(r, 200, nil))
+	a.Mul(&u, &v)
+	a.Apply(func(_, _ int, x float64) float64 { return x + 1e-6*rnd.NormFloat64() }, a)
+
+	want, err := Factorize(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := Randomized(a, r, 10, 1, rnd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, s := range got.Sigma {
+		if !withinRel(s, want.Sigma[i], 1e-8) {
+			t.Errorf("unexpected singular value %d: got:%v want:%v", i, s, want.Sigma[i])
+		}
+	}
+	var approx mat.Dense
+	got.Approx(&approx, r)
+	var diff mat.Dense
+	diff.Sub(a, &approx)
+	if e := mat.Norm(&diff, 2) / mat.Norm(a, 2); e > 1e-6 {
+		t.Errorf("unexpected relative approximation error: got:%v want:<1e-6", e)
+	}
+
+	// A nil source uses a fixed seed that does not depend on the
+	// demo seed environment variable.
+	old, ok := os.LookupEnv("DATABOOK_SEED")
+	defer func() {
+		if ok {
+			os.Setenv("DATABOOK_SEED", old)
+		} else {
+			os.Unsetenv("DATABOOK_SEED")
+		}
+	}()
+	os.Setenv("DATABOOK_SEED", "42")
+	first, err := Randomized(a, r, 2, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.Setenv("DATABOOK_SEED", "invalid")
+	second, err := Randomized(a, r, 2, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.Equal(first.U, second.U) {
+		t.Error("randomized SVD with nil source depends on the environment")
+	}
+}
+
+func withinRel(a, b, tol float64) bool {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	return d <= tol*b
+}
+
+// The benchmarks below compare the speed of the randomized and full thin
+// SVD of the dog.jpg image, and report the relative Frobenius error of the
+// rank 100 approximation as the relerr metric.
+
+func BenchmarkSVDDog(b *testing.B) {
+	a := dog(b)
+	b.ResetTimer()
+	var svd *SVD
+	for i := 0; i < b.N; i++ {
+		var err error
+		svd, err = Factorize(a)
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(approxError(a, svd, 100), "relerr")
+}
+
+func BenchmarkRandomizedDog(b *testing.B) {
+	a := dog(b)
+	for _, power := range []int{0, 1, 2} {
+		b.Run(fmt.Sprintf("power=%d", power), func(b *testing.B) {
+			rnd := rand.New(rand.NewSource(1))
+			var svd *SVD
+			for i := 0; i < b.N; i++ {
+				var err error
+				svd, err = Randomized(a, 100, 10, power, rnd)
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(approxError(a, svd, 100), "relerr")
+		})
+	}
+}
+
+func approxError(a *mat.Dense, svd *SVD, r int) float64 {
+	var approx mat.Dense
+	svd.Approx(&approx, r)
+	approx.Sub(a, &approx)
+	return mat.Norm(&approx, 2) / mat.Norm(a, 2)
+}
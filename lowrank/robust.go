@@ -0,0 +1,186 @@
+package lowrank
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrNotConverged is returned when an iterative decomposition does not
+// reach its tolerance within the allowed number of iterations.
+var ErrNotConverged = errors.New("lowrank: decomposition did not converge")
+
+// RobustOptions holds parameters for Robust. Zero values select the
+// defaults.
+type RobustOptions struct {
+	// Lambda is the weight of the sparse component
+	// in the objective. The default is 1/√max(m, n)
+	// for an m×n matrix.
+	Lambda float64
+
+	// Tol is the relative Frobenius norm of the
+	// residual, ‖X - L - S‖_F / ‖X‖_F, at which the
+	// iteration is considered converged. The default
+	// is 1e-7.
+	Tol float64
+
+	// MaxIter is the maximum number of iterations.
+	// The default is 1000.
+	MaxIter int
+
+	// Rho is the factor by which the penalty
+	// parameter is increased at each iteration. It
+	// must be greater than one. The default is 1.5.
+	Rho float64
+}
+
+// RobustPCA is the decomposition of a matrix into low-rank and sparse
+// components, X = L + S.
+type RobustPCA struct {
+	// L and S are the low-rank and sparse components.
+	L, S *mat.Dense
+
+	// Rank is the rank of L.
+	Rank int
+
+	// Iterations is the number of iterations performed.
+	Iterations int
+}
+
+// Robust returns the robust principal component analysis of x, the
+// decomposition of x into a low-rank matrix L and a sparse matrix S,
+// computed by principal component pursuit using the inexact augmented
+// Lagrange multiplier method of Lin, Chen and Ma, "The Augmented Lagrange
+// Multiplier Method for Exact Recovery of Corrupted Low-Rank Matrices",
+// arXiv:1009.5055, 2010.
+//
+// If opts is nil, the default options are used. An error is returned if
+// opts.Rho is not greater than one. If the iteration does not converge,
+// the last decomposition is returned with ErrNotConverged.
+func Robust(x mat.Matrix, opts *RobustOptions) (*RobustPCA, error) {
+	var o RobustOptions
+	if opts != nil {
+		o = *opts
+	}
+	rows, cols := x.Dims()
+	if o.Lambda == 0 {
+		o.Lambda = 1 / math.Sqrt(float64(maxInt(rows, cols)))
+	}
+	if o.Tol == 0 {
+		o.Tol = 1e-7
+	}
+	if o.MaxIter == 0 {
+		o.MaxIter = 1000
+	}
+	if o.Rho == 0 {
+		o.Rho = 1.5
+	}
+	if !(o.Rho > 1) {
+		return nil, fmt.Errorf("lowrank: invalid penalty growth factor: %v", o.Rho)
+	}
+
+	xd := mat.DenseCopyOf(x)
+	normF := mat.Norm(xd, 2)
+	if normF == 0 {
+		return &RobustPCA{L: mat.NewDense(rows, cols, nil), S: mat.NewDense(rows, cols, nil)}, nil
+	}
+	var svd mat.SVD
+	ok := svd.Factorize(xd, mat.SVDNone)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	norm2 := svd.Values(nil)[0]
+	var normMax float64
+	for i := 0; i < rows; i++ {
+		for _, v := range xd.RawRowView(i) {
+			normMax = math.Max(normMax, math.Abs(v))
+		}
+	}
+
+	// Initialize the dual variable so that it is
+	// feasible for the dual problem.
+	var y mat.Dense
+	y.Scale(1/math.Max(norm2, normMax/o.Lambda), xd)
+	mu := 1.25 / norm2
+	muMax := mu * 1e7
+
+	r := &RobustPCA{
+		L: mat.NewDense(rows, cols, nil),
+		S: mat.NewDense(rows, cols, nil),
+	}
+	var work, z mat.Dense
+	for r.Iterations < o.MaxIter {
+		r.Iterations++
+
+		// S = shrink(X - L + Y/μ, λ/μ)
+		work.Sub(xd, r.L)
+		work.Apply(func(i, j int, v float64) float64 {
+			return shrink(v+y.At(i, j)/mu, o.Lambda/mu)
+		}, &work)
+		r.S.Copy(&work)
+
+		// L = SVT(X - S + Y/μ, 1/μ)
+		work.Sub(xd, r.S)
+		work.Apply(func(i, j int, v float64) float64 {
+			return v + y.At(i, j)/mu
+		}, &work)
+		rank, err := svt(r.L, &work, 1/mu)
+		if err != nil {
+			return nil, err
+		}
+		r.Rank = rank
+
+		// Y = Y + μ(X - L - S)
+		z.Sub(xd, r.L)
+		z.Sub(&z, r.S)
+		if mat.Norm(&z, 2)/normF < o.Tol {
+			return r, nil
+		}
+		y.Apply(func(i, j int, v float64) float64 {
+			return v + mu*z.At(i, j)
+		}, &y)
+		mu = math.Min(mu*o.Rho, muMax)
+	}
+	return r, ErrNotConverged
+}
+
+// svt places the singular value thresholding of a at tau into dst and
+// returns the rank of the result.
+func svt(dst *mat.Dense, a *mat.Dense, tau float64) (int, error) {
+	s, err := Factorize(a)
+	if err != nil {
+		return 0, err
+	}
+	var rank int
+	for i, v := range s.Sigma {
+		v = shrink(v, tau)
+		if v == 0 {
+			break
+		}
+		s.Sigma[i] = v
+		rank++
+	}
+	s.Approx(dst, rank)
+	return rank, nil
+}
+
+// shrink returns the soft thresholding of v at tau.
+func shrink(v, tau float64) float64 {
+	switch {
+	case v > tau:
+		return v - tau
+	case v < -tau:
+		return v + tau
+	default:
+		return 0
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
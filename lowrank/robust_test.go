@@ -0,0 +1,87 @@
+package lowrank
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRobustRecovery(t *testing.T) {
+	const (
+		rows = 100
+		cols = 80
+		rank = 4
+	)
+	rnd := rand.New(rand.NewSource(1))
+	low := randRank(rows, cols, rank, rnd)
+
+	// Corrupt 5% of the entries with large errors.
+	sparse := mat.NewDense(rows, cols, nil)
+	for n := 0; n < rows*cols/20; {
+		i, j := rnd.Intn(rows), rnd.Intn(cols)
+		if sparse.At(i, j) != 0 {
+			continue
+		}
+		sparse.Set(i, j, 20*(rnd.Float64()-0.5))
+		n++
+	}
+	var x mat.Dense
+	x.Add(low, sparse)
+
+	r, err := Robust(&x, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Rank != rank {
+		t.Errorf("unexpected rank: got:%d want:%d", r.Rank, rank)
+	}
+	for _, test := range []struct {
+		name      string
+		got, want *mat.Dense
+	}{
+		{name: "low-rank", got: r.L, want: low},
+		{name: "sparse", got: r.S, want: sparse},
+	} {
+		var diff mat.Dense
+		diff.Sub(test.got, test.want)
+		if e := mat.Norm(&diff, 2) / mat.Norm(test.want, 2); e > 1e-5 {
+			t.Errorf("unexpected relative error in %s component: got:%v want:<1e-5", test.name, e)
+		}
+	}
+
+	var sum mat.Dense
+	sum.Add(r.L, r.S)
+	if !mat.EqualApprox(&sum, &x, 1e-5*mat.Norm(&x, 2)) {
+		t.Error("components do not sum to the input")
+	}
+
+	_, err = Robust(&x, &RobustOptions{MaxIter: 2})
+	if err != ErrNotConverged {
+		t.Errorf("unexpected error for limited iterations: got:%v want:%v", err, ErrNotConverged)
+	}
+}
+
+func TestRobustZero(t *testing.T) {
+	r, err := Robust(mat.NewDense(3, 4, nil), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Rank != 0 || mat.Norm(r.L, 2) != 0 || mat.Norm(r.S, 2) != 0 {
+		t.Errorf("unexpected decomposition of zero matrix: rank=%d", r.Rank)
+	}
+}
+
+func TestRobustInvalidRho(t *testing.T) {
+	x := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	for _, rho := range []float64{-1, 0.5, 1, math.NaN()} {
+		r, err := Robust(x, &RobustOptions{Rho: rho})
+		if err == nil {
+			t.Errorf("expected error for rho=%v", rho)
+		}
+		if r != nil {
+			t.Errorf("unexpected decomposition for rho=%v", rho)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package lowrank
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/integrate/quad"
+)
+
+// OptimalThreshold returns the optimal hard threshold for the singular
+// values, sigma, of a rows×cols matrix corrupted by white noise, as
+// described by Gavish and Donoho in "The Optimal Hard Threshold for
+// Singular Values is 4/√3", IEEE Trans. Inf. Theory 60(8), 2014.
+//
+// If noise is positive it is taken as the known standard deviation of the
+// noise. Otherwise the noise level is considered unknown and the threshold
+// is estimated from the median singular value.
+func OptimalThreshold(sigma []float64, rows, cols int, noise float64) float64 {
+	m, n := rows, cols
+	if m > n {
+		m, n = n, m
+	}
+	beta := float64(m) / float64(n)
+	if noise > 0 {
+		return lambdaStar(beta) * math.Sqrt(float64(n)) * noise
+	}
+	return omega(beta) * median(sigma)
+}
+
+// OptimalRank returns the number of singular values in sigma that are
+// greater than the optimal hard threshold for a rows×cols matrix. See
+// OptimalThreshold for the interpretation of noise.
+func OptimalRank(sigma []float64, rows, cols int, noise float64) int {
+	tau := OptimalThreshold(sigma, rows, cols, noise)
+	var r int
+	for _, s := range sigma {
+		if s > tau {
+			r++
+		}
+	}
+	return r
+}
+
+// lambdaStar returns the optimal threshold coefficient for known noise
+// and aspect ratio beta in (0, 1].
+func lambdaStar(beta float64) float64 {
+	return math.Sqrt(2*(beta+1) + 8*beta/(beta+1+math.Sqrt(beta*beta+14*beta+1)))
+}
+
+// omega returns the optimal threshold coefficient for unknown noise,
+// applied to the median singular value, for aspect ratio beta in (0, 1].
+func omega(beta float64) float64 {
+	return lambdaStar(beta) / math.Sqrt(marchenkoPasturMedian(beta))
+}
+
+// marchenkoPasturMedian returns the median of the Marchenko-Pastur
+// distribution with unit variance and aspect ratio beta in (0, 1].
+func marchenkoPasturMedian(beta float64) float64 {
+	lo := (1 - math.Sqrt(beta)) * (1 - math.Sqrt(beta))
+	hi := (1 + math.Sqrt(beta)) * (1 + math.Sqrt(beta))
+
+	// The substitution t = lo + (hi-lo)(1-cos θ)/2 removes the
+	// square root singularities of the density at the ends of
+	// its support.
+	half := (hi - lo) / 2
+	density := func(theta float64) float64 {
+		t := lo + half*(1-math.Cos(theta))
+		s := half * math.Sin(theta)
+		if t == 0 {
+			// The limit as θ → 0 when beta is 1.
+			return half / (math.Pi * beta)
+		}
+		return s * s / (2 * math.Pi * beta * t)
+	}
+	cdf := func(theta float64) float64 {
+		return quad.Fixed(density, 0, theta, 200, nil, 0)
+	}
+
+	// Bisect for the angle at which the distribution reaches 1/2.
+	a, b := 0.0, math.Pi
+	for i := 0; i < 60; i++ {
+		mid := (a + b) / 2
+		if cdf(mid) < 0.5 {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return lo + half*(1-math.Cos((a+b)/2))
+}
+
+func median(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	s := append([]float64(nil), x...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
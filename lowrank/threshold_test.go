@@ -0,0 +1,75 @@
+package lowrank
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Values of λ*(β) and ω(β) given by Gavish and Donoho.
+var thresholdTests = []struct {
+	beta   float64
+	lambda float64
+	omega  float64
+}{
+	{beta: 0.05, lambda: 1.5066, omega: 1.519},
+	{beta: 0.10, lambda: 1.5816, omega: 1.609},
+	{beta: 0.50, lambda: 1.9786, omega: 2.171},
+	{beta: 1.00, lambda: 2.3094, omega: 2.858},
+}
+
+func TestThresholdCoefficients(t *testing.T) {
+	for _, test := range thresholdTests {
+		if got := lambdaStar(test.beta); math.Abs(got-test.lambda) > 5e-5 {
+			t.Errorf("unexpected λ*(%v): got:%.5f want:%.4f", test.beta, got, test.lambda)
+		}
+		if got := omega(test.beta); math.Abs(got-test.omega) > 5e-4 {
+			t.Errorf("unexpected ω(%v): got:%.4f want:%.3f", test.beta, got, test.omega)
+		}
+	}
+	if got, want := lambdaStar(1), 4/math.Sqrt(3); math.Abs(got-want) > 1e-15 {
+		t.Errorf("unexpected λ*(1): got:%v want:4/√3=%v", got, want)
+	}
+}
+
+func TestOptimalRank(t *testing.T) {
+	const (
+		rows  = 200
+		cols  = 100
+		rank  = 3
+		noise = 0.1
+	)
+	rnd := rand.New(rand.NewSource(1))
+	signal := randRank(rows, cols, rank, rnd)
+	a := mat.NewDense(rows, cols, nil)
+	a.Apply(func(i, j int, v float64) float64 { return v + noise*rnd.NormFloat64() }, signal)
+	svd, err := Factorize(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := lambdaStar(0.5) * math.Sqrt(rows) * noise
+	if got := OptimalThreshold(svd.Sigma, rows, cols, noise); got != want {
+		t.Errorf("unexpected known noise threshold: got:%v want:%v", got, want)
+	}
+	if got := OptimalThreshold(svd.Sigma, cols, rows, noise); got != want {
+		t.Errorf("unexpected known noise threshold for transpose: got:%v want:%v", got, want)
+	}
+	for _, n := range []float64{noise, 0} {
+		if got := OptimalRank(svd.Sigma, rows, cols, n); got != rank {
+			t.Errorf("unexpected optimal rank with noise=%v: got:%d want:%d", n, got, rank)
+		}
+	}
+
+	// Pure noise has no singular values above the threshold.
+	a.Apply(func(_, _ int, _ float64) float64 { return noise * rnd.NormFloat64() }, a)
+	svd, err = Factorize(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := OptimalRank(svd.Sigma, rows, cols, noise); got != 0 {
+		t.Errorf("unexpected optimal rank for noise: got:%d want:0", got)
+	}
+}
@@ -0,0 +1,311 @@
+// Package matfile provides reading and writing of MATLAB level 5 MAT-files.
+//
+// The MAT-file format is described in the MathWorks document
+// "MAT-File Format", https://www.mathworks.com/help/pdf_doc/matlab/matfile_format.pdf.
+// Only the level 5 format is supported; HDF5-based v7.3 files are not.
+package matfile
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Class is a MATLAB array class.
+type Class uint8
+
+// MATLAB array classes.
+const (
+	CellClass     Class = 1
+	StructClass   Class = 2
+	ObjectClass   Class = 3
+	CharClass     Class = 4
+	SparseClass   Class = 5
+	DoubleClass   Class = 6
+	SingleClass   Class = 7
+	Int8Class     Class = 8
+	Uint8Class    Class = 9
+	Int16Class    Class = 10
+	Uint16Class   Class = 11
+	Int32Class    Class = 12
+	Uint32Class   Class = 13
+	Int64Class    Class = 14
+	Uint64Class   Class = 15
+	FunctionClass Class = 16
+	OpaqueClass   Class = 17
+)
+
+var classNames = [...]string{
+	CellClass:     "cell",
+	StructClass:   "struct",
+	ObjectClass:   "object",
+	CharClass:     "char",
+	SparseClass:   "sparse",
+	DoubleClass:   "double",
+	SingleClass:   "single",
+	Int8Class:     "int8",
+	Uint8Class:    "uint8",
+	Int16Class:    "int16",
+	Uint16Class:   "uint16",
+	Int32Class:    "int32",
+	Uint32Class:   "uint32",
+	Int64Class:    "int64",
+	Uint64Class:   "uint64",
+	FunctionClass: "function_handle",
+	OpaqueClass:   "opaque",
+}
+
+func (c Class) String() string {
+	if int(c) < len(classNames) && classNames[c] != "" {
+		return classNames[c]
+	}
+	return fmt.Sprintf("Class(%d)", c)
+}
+
+// isNumeric returns whether the class holds numeric data.
+func (c Class) isNumeric() bool {
+	return c == SparseClass || (DoubleClass <= c && c <= Uint64Class)
+}
+
+// File is the decoded contents of a MAT-file.
+type File struct {
+	// Header is the descriptive text of the file header.
+	Header string
+
+	// Vars is the set of variables held by the file
+	// in the order they were stored.
+	Vars []*Var
+}
+
+// Var returns the variable with the given name, or nil
+// if no variable with that name exists in the file.
+func (f *File) Var(name string) *Var {
+	for _, v := range f.Vars {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Dense returns the named variable as a *mat.Dense.
+func (f *File) Dense(name string) (*mat.Dense, error) {
+	v, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.Dense()
+}
+
+// CDense returns the named variable as a *mat.CDense.
+func (f *File) CDense(name string) (*mat.CDense, error) {
+	v, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.CDense()
+}
+
+// Strings returns the named variable as a []string.
+func (f *File) Strings(name string) ([]string, error) {
+	v, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.Strings()
+}
+
+func (f *File) lookup(name string) (*Var, error) {
+	v := f.Var(name)
+	if v == nil {
+		return nil, fmt.Errorf("matfile: no variable %q", name)
+	}
+	return v, nil
+}
+
+// Var is a MATLAB array.
+type Var struct {
+	// Name is the name of the variable. Name is
+	// empty for cell elements and struct field values.
+	Name string
+
+	// Class is the MATLAB class of the array.
+	Class Class
+
+	// Dims holds the dimensions of the array. Dims has
+	// at least two elements except for opaque objects and
+	// function handles, which are not decoded.
+	Dims []int
+
+	// IsComplex, IsGlobal and IsLogical hold the
+	// array flags for the variable.
+	IsComplex bool
+	IsGlobal  bool
+	IsLogical bool
+
+	// Real and Imag hold the real and imaginary parts
+	// of numeric and sparse arrays in column-major order.
+	// Sparse arrays are expanded to their dense form.
+	// Imag is nil unless IsComplex is true. For char
+	// arrays, Real holds the character code points.
+	Real, Imag []float64
+
+	// Cells holds the elements of a cell array in
+	// column-major order.
+	Cells []*Var
+
+	// Fields holds the field names of a struct array.
+	Fields []string
+
+	// Values holds the field values of each element of
+	// a struct array. The elements are in column-major
+	// order and for each element the values are in the
+	// order of Fields.
+	Values []*Var
+
+	// ClassName is the class name of an object.
+	ClassName string
+}
+
+// Len returns the number of elements in the array.
+func (v *Var) Len() int {
+	n := 1
+	for _, d := range v.Dims {
+		n *= d
+	}
+	return n
+}
+
+// matrixDims returns the dimensions of v viewed as a matrix.
+// Trailing dimensions are folded into the column count so
+// an m×n×p array is viewed as an m×(n*p) matrix.
+func (v *Var) matrixDims() (r, c int) {
+	if len(v.Dims) == 0 {
+		return 0, 0
+	}
+	r = v.Dims[0]
+	c = 1
+	for _, d := range v.Dims[1:] {
+		c *= d
+	}
+	return r, c
+}
+
+// ErrEmpty is returned when converting an empty array
+// to a matrix.
+var ErrEmpty = errors.New("matfile: empty array")
+
+// Dense returns the real part of a numeric, char or logical array
+// as a *mat.Dense. Arrays with more than two dimensions are returned
+// with trailing dimensions folded into the columns.
+func (v *Var) Dense() (*mat.Dense, error) {
+	if !v.Class.isNumeric() && v.Class != CharClass {
+		return nil, fmt.Errorf("matfile: %s is not numeric: %v", v.name(), v.Class)
+	}
+	r, c := v.matrixDims()
+	if r == 0 || c == 0 {
+		return nil, ErrEmpty
+	}
+	m := mat.NewDense(r, c, nil)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			m.Set(i, j, v.Real[i+j*r])
+		}
+	}
+	return m, nil
+}
+
+// CDense returns a numeric array as a *mat.CDense. Real arrays are
+// returned with a zero imaginary part. Arrays with more than two
+// dimensions are returned with trailing dimensions folded into the
+// columns.
+func (v *Var) CDense() (*mat.CDense, error) {
+	if !v.Class.isNumeric() {
+		return nil, fmt.Errorf("matfile: %s is not numeric: %v", v.name(), v.Class)
+	}
+	r, c := v.matrixDims()
+	if r == 0 || c == 0 {
+		return nil, ErrEmpty
+	}
+	m := mat.NewCDense(r, c, nil)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			var im float64
+			if v.Imag != nil {
+				im = v.Imag[i+j*r]
+			}
+			m.Set(i, j, complex(v.Real[i+j*r], im))
+		}
+	}
+	return m, nil
+}
+
+// Strings returns the rows of a char array, or the elements of a cell
+// array of char arrays, as a []string.
+func (v *Var) Strings() ([]string, error) {
+	switch v.Class {
+	case CharClass:
+		r, c := v.matrixDims()
+		s := make([]string, r)
+		row := make([]rune, c)
+		for i := range s {
+			for j := range row {
+				row[j] = rune(v.Real[i+j*r])
+			}
+			s[i] = string(row)
+		}
+		return s, nil
+	case CellClass:
+		var s []string
+		for _, e := range v.Cells {
+			if e.Len() == 0 {
+				// Empty elements carry no class information
+				// when stored without an array subelement.
+				s = append(s, "")
+				continue
+			}
+			if e.Class != CharClass {
+				return nil, fmt.Errorf("matfile: %s cell element is not char: %v", v.name(), e.Class)
+			}
+			es, err := e.Strings()
+			if err != nil {
+				return nil, err
+			}
+			switch len(es) {
+			case 0:
+				s = append(s, "")
+			default:
+				s = append(s, es...)
+			}
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("matfile: %s is not char or cell: %v", v.name(), v.Class)
+	}
+}
+
+// Field returns the value of the named field for the ith element of a
+// struct array, or nil if v is not a struct, has no such field or i is
+// out of range.
+func (v *Var) Field(name string, i int) *Var {
+	if v.Class != StructClass && v.Class != ObjectClass {
+		return nil
+	}
+	if i < 0 || i >= v.Len() {
+		return nil
+	}
+	for k, f := range v.Fields {
+		if f == name {
+			return v.Values[i*len(v.Fields)+k]
+		}
+	}
+	return nil
+}
+
+func (v *Var) name() string {
+	if v.Name == "" {
+		return "<unnamed>"
+	}
+	return v.Name
+}
@@ -0,0 +1,488 @@
+package matfile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MAT-file data types.
+const (
+	miINT8       = 1
+	miUINT8      = 2
+	miINT16      = 3
+	miUINT16     = 4
+	miINT32      = 5
+	miUINT32     = 6
+	miSINGLE     = 7
+	miDOUBLE     = 9
+	miINT64      = 12
+	miUINT64     = 13
+	miMATRIX     = 14
+	miCOMPRESSED = 15
+	miUTF8       = 16
+	miUTF16      = 17
+	miUTF32      = 18
+)
+
+// Array flag bits.
+const (
+	flagComplex = 0x08
+	flagGlobal  = 0x04
+	flagLogical = 0x02
+)
+
+const headerLen = 128
+
+// Open reads the MAT-file at the given path.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads a MAT-file from r.
+func Read(r io.Reader) (*File, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < headerLen {
+		return nil, errors.New("matfile: short header")
+	}
+	var d decoder
+	switch string(b[126:128]) {
+	case "IM":
+		d.order = binary.LittleEndian
+	case "MI":
+		d.order = binary.BigEndian
+	default:
+		return nil, errors.New("matfile: invalid endian indicator")
+	}
+	if v := d.order.Uint16(b[124:126]); v != 0x0100 {
+		if v == 0x0200 {
+			return nil, errors.New("matfile: HDF5-based MAT-files are not supported")
+		}
+		return nil, fmt.Errorf("matfile: unsupported version: %#x", v)
+	}
+	// The subsystem data offset is either zero or all spaces
+	// when there is no subsystem data.
+	subsys := int64(d.order.Uint64(b[116:124]))
+	if subsys == 0x2020202020202020 {
+		subsys = 0
+	}
+
+	f := &File{Header: string(bytes.TrimRight(b[:116], " \x00"))}
+	off := headerLen
+	for off < len(b) {
+		start := off
+		typ, data, n, err := d.element(b[off:], true)
+		if err != nil {
+			return nil, fmt.Errorf("matfile: element at offset %d: %w", start, err)
+		}
+		off += n
+		if subsys != 0 && int64(start) == subsys {
+			// Skip subsystem-specific data.
+			continue
+		}
+		if typ == miCOMPRESSED {
+			data, err = inflate(data)
+			if err != nil {
+				return nil, fmt.Errorf("matfile: element at offset %d: %w", start, err)
+			}
+			typ, data, _, err = d.element(data, false)
+			if err != nil {
+				return nil, fmt.Errorf("matfile: compressed element at offset %d: %w", start, err)
+			}
+		}
+		if typ != miMATRIX {
+			// Top-level elements other than arrays are not
+			// meaningful to the user.
+			continue
+		}
+		v, err := d.matrix(data)
+		if err != nil {
+			return nil, fmt.Errorf("matfile: element at offset %d: %w", start, err)
+		}
+		f.Vars = append(f.Vars, v)
+	}
+	return f, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type decoder struct {
+	order binary.ByteOrder
+}
+
+// element returns the type and data of the data element at the start of b
+// and the number of bytes consumed by the element including padding. If
+// top is true, compressed elements are not padded.
+func (d decoder) element(b []byte, top bool) (typ uint32, data []byte, n int, err error) {
+	if len(b) < 4 {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+	tag := d.order.Uint32(b)
+	if size := int(tag >> 16); size != 0 {
+		// Small data element format.
+		if size > 4 {
+			return 0, nil, 0, fmt.Errorf("invalid small element size: %d", size)
+		}
+		if len(b) < 4+size {
+			return 0, nil, 0, io.ErrUnexpectedEOF
+		}
+		return tag & 0xffff, b[4 : 4+size], min(8, len(b)), nil
+	}
+	if len(b) < 8 {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+	typ = tag
+	size := int(d.order.Uint32(b[4:]))
+	if size > len(b)-8 {
+		return 0, nil, 0, io.ErrUnexpectedEOF
+	}
+	n = 8 + size
+	if !(top && typ == miCOMPRESSED) {
+		n += pad(size)
+	}
+	return typ, b[8 : 8+size], min(n, len(b)), nil
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// pad returns the number of bytes required to align n to 8 bytes.
+func pad(n int) int {
+	return (8 - n%8) % 8
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// matrix decodes the data of an miMATRIX element.
+func (d decoder) matrix(b []byte) (*Var, error) {
+	if len(b) == 0 {
+		// Empty arrays, typically as cell elements
+		// or struct field values.
+		return &Var{Class: DoubleClass, Dims: []int{0, 0}, Real: []float64{}}, nil
+	}
+
+	typ, data, n, err := d.element(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miUINT32 || len(data) != 8 {
+		return nil, errors.New("invalid array flags")
+	}
+	b = b[n:]
+	flags := d.order.Uint32(data)
+	v := &Var{
+		Class:     Class(flags & 0xff),
+		IsComplex: flags&(flagComplex<<8) != 0,
+		IsGlobal:  flags&(flagGlobal<<8) != 0,
+		IsLogical: flags&(flagLogical<<8) != 0,
+	}
+
+	if v.Class == OpaqueClass || v.Class == FunctionClass {
+		// Opaque objects and function handles are stored in
+		// an undocumented form. Only the name is retained.
+		typ, data, _, err = d.element(b, false)
+		if err == nil && typ == miINT8 {
+			v.Name = string(data)
+		}
+		return v, nil
+	}
+
+	typ, data, n, err = d.element(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miINT32 {
+		return nil, errors.New("invalid dimensions")
+	}
+	b = b[n:]
+	for i := 0; i+4 <= len(data); i += 4 {
+		v.Dims = append(v.Dims, int(int32(d.order.Uint32(data[i:]))))
+	}
+	if len(v.Dims) < 2 {
+		return nil, fmt.Errorf("invalid dimensions: %v", v.Dims)
+	}
+	// Reject dimensions that are negative or whose product
+	// overflows, so that v.Len is valid.
+	elems := 1
+	for _, dim := range v.Dims {
+		if dim < 0 || (dim != 0 && elems > maxInt/dim) {
+			return nil, fmt.Errorf("invalid dimensions: %v", v.Dims)
+		}
+		elems *= dim
+	}
+
+	typ, data, n, err = d.element(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if typ != miINT8 {
+		return nil, errors.New("invalid array name")
+	}
+	b = b[n:]
+	v.Name = string(data)
+
+	switch {
+	case v.Class == CellClass:
+		// Each cell is an element with at least a tag.
+		if v.Len() > len(b)/8 {
+			return nil, fmt.Errorf("%s: %d cells do not fit in %d bytes", v.name(), v.Len(), len(b))
+		}
+		v.Cells = make([]*Var, v.Len())
+		for i := range v.Cells {
+			v.Cells[i], b, err = d.subMatrix(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s cell %d: %w", v.name(), i, err)
+			}
+		}
+	case v.Class == StructClass || v.Class == ObjectClass:
+		if v.Class == ObjectClass {
+			typ, data, n, err = d.element(b, false)
+			if err != nil {
+				return nil, err
+			}
+			if typ != miINT8 {
+				return nil, errors.New("invalid class name")
+			}
+			b = b[n:]
+			v.ClassName = string(data)
+		}
+		typ, data, n, err = d.element(b, false)
+		if err != nil {
+			return nil, err
+		}
+		if typ != miINT32 || len(data) != 4 {
+			return nil, errors.New("invalid field name length")
+		}
+		b = b[n:]
+		width := int(d.order.Uint32(data))
+		typ, data, n, err = d.element(b, false)
+		if err != nil {
+			return nil, err
+		}
+		if typ != miINT8 || (width != 0 && len(data)%width != 0) {
+			return nil, errors.New("invalid field names")
+		}
+		b = b[n:]
+		for i := 0; i+width <= len(data) && width != 0; i += width {
+			v.Fields = append(v.Fields, string(bytes.TrimRight(data[i:i+width], "\x00")))
+		}
+		// Each field value is an element with at least a tag.
+		if len(v.Fields) != 0 && v.Len() > len(b)/8/len(v.Fields) {
+			return nil, fmt.Errorf("%s: %d×%d field values do not fit in %d bytes", v.name(), v.Len(), len(v.Fields), len(b))
+		}
+		v.Values = make([]*Var, v.Len()*len(v.Fields))
+		for i := range v.Values {
+			v.Values[i], b, err = d.subMatrix(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s field %s: %w", v.name(), v.Fields[i%len(v.Fields)], err)
+			}
+		}
+	case v.Class == SparseClass:
+		err = d.sparse(v, b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.name(), err)
+		}
+	case v.Class == CharClass:
+		typ, data, _, err = d.element(b, false)
+		if err != nil {
+			return nil, err
+		}
+		v.Real, err = d.chars(typ, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.name(), err)
+		}
+		if len(v.Real) != v.Len() {
+			return nil, fmt.Errorf("%s: data length does not match dimensions %v", v.name(), v.Dims)
+		}
+	case v.Class.isNumeric():
+		typ, data, n, err = d.element(b, false)
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+		v.Real, err = d.numeric(typ, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s real part: %w", v.name(), err)
+		}
+		if v.IsComplex {
+			typ, data, _, err = d.element(b, false)
+			if err != nil {
+				return nil, err
+			}
+			v.Imag, err = d.numeric(typ, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s imaginary part: %w", v.name(), err)
+			}
+		}
+		if len(v.Real) != v.Len() || (v.IsComplex && len(v.Imag) != v.Len()) {
+			return nil, fmt.Errorf("%s: data length does not match dimensions %v", v.name(), v.Dims)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported class: %v", v.name(), v.Class)
+	}
+	return v, nil
+}
+
+// subMatrix decodes the miMATRIX element at the start of b, returning the
+// decoded array and the remaining data.
+func (d decoder) subMatrix(b []byte) (*Var, []byte, error) {
+	typ, data, n, err := d.element(b, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if typ != miMATRIX {
+		return nil, nil, fmt.Errorf("unexpected element type: %d", typ)
+	}
+	v, err := d.matrix(data)
+	return v, b[n:], err
+}
+
+// sparse decodes the row indices, column offsets and values of a
+// sparse array in b into dense form in v.
+func (d decoder) sparse(v *Var, b []byte) error {
+	var idx [2][]float64
+	for i := range idx {
+		typ, data, n, err := d.element(b, false)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		idx[i], err = d.numeric(typ, data)
+		if err != nil {
+			return err
+		}
+	}
+	ir, jc := idx[0], idx[1]
+	if len(v.Dims) != 2 || len(jc) != v.Dims[1]+1 {
+		return errors.New("invalid sparse column index")
+	}
+
+	parts := []*[]float64{&v.Real}
+	if v.IsComplex {
+		parts = append(parts, &v.Imag)
+	}
+	rows := v.Dims[0]
+	for _, p := range parts {
+		typ, data, n, err := d.element(b, false)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+		vals, err := d.numeric(typ, data)
+		if err != nil {
+			return err
+		}
+		dense := make([]float64, v.Len())
+		for j := 0; j < v.Dims[1]; j++ {
+			for k := int(jc[j]); k < int(jc[j+1]); k++ {
+				if k >= len(vals) || k >= len(ir) || int(ir[k]) >= rows {
+					return errors.New("invalid sparse index")
+				}
+				dense[int(ir[k])+j*rows] = vals[k]
+			}
+		}
+		*p = dense
+	}
+	return nil
+}
+
+// chars decodes character data into code points.
+func (d decoder) chars(typ uint32, data []byte) ([]float64, error) {
+	switch typ {
+	case miUTF8:
+		c := make([]float64, 0, utf8.RuneCount(data))
+		for len(data) != 0 {
+			r, n := utf8.DecodeRune(data)
+			c = append(c, float64(r))
+			data = data[n:]
+		}
+		return c, nil
+	case miUTF16:
+		u := make([]uint16, len(data)/2)
+		for i := range u {
+			u[i] = d.order.Uint16(data[2*i:])
+		}
+		r := utf16.Decode(u)
+		c := make([]float64, len(r))
+		for i, v := range r {
+			c[i] = float64(v)
+		}
+		return c, nil
+	default:
+		return d.numeric(typ, data)
+	}
+}
+
+// numeric decodes numeric data of the given type into float64 values.
+func (d decoder) numeric(typ uint32, data []byte) ([]float64, error) {
+	var size int
+	switch typ {
+	case miINT8, miUINT8, miUTF8:
+		size = 1
+	case miINT16, miUINT16, miUTF16:
+		size = 2
+	case miINT32, miUINT32, miSINGLE, miUTF32:
+		size = 4
+	case miDOUBLE, miINT64, miUINT64:
+		size = 8
+	default:
+		return nil, fmt.Errorf("unsupported data type: %d", typ)
+	}
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("invalid data length %d for type %d", len(data), typ)
+	}
+	v := make([]float64, len(data)/size)
+	for i := range v {
+		b := data[i*size:]
+		switch typ {
+		case miINT8:
+			v[i] = float64(int8(b[0]))
+		case miUINT8, miUTF8:
+			v[i] = float64(b[0])
+		case miINT16:
+			v[i] = float64(int16(d.order.Uint16(b)))
+		case miUINT16, miUTF16:
+			v[i] = float64(d.order.Uint16(b))
+		case miINT32:
+			v[i] = float64(int32(d.order.Uint32(b)))
+		case miUINT32, miUTF32:
+			v[i] = float64(d.order.Uint32(b))
+		case miSINGLE:
+			v[i] = float64(math.Float32frombits(d.order.Uint32(b)))
+		case miDOUBLE:
+			v[i] = math.Float64frombits(d.order.Uint64(b))
+		case miINT64:
+			v[i] = float64(int64(d.order.Uint64(b)))
+		case miUINT64:
+			v[i] = float64(d.order.Uint64(b))
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,272 @@
+package matfile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The helpers below construct little-endian MAT-file data
+// independently of the encoder used by Writer.
+
+// testHeader returns a MAT-file header with no subsystem data.
+func testHeader() []byte {
+	h := bytes.Repeat([]byte{' '}, headerLen)
+	copy(h, "MATLAB 5.0 MAT-file, test")
+	binary.LittleEndian.PutUint16(h[124:], 0x0100)
+	copy(h[126:], "IM")
+	return h
+}
+
+// testElement returns a data element in the normal format, padded
+// to an 8 byte boundary unless nopad is true.
+func testElement(typ uint32, data []byte, nopad bool) []byte {
+	b := make([]byte, 8, 8+len(data)+7)
+	binary.LittleEndian.PutUint32(b, typ)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+	if !nopad {
+		b = append(b, make([]byte, pad(len(data)))...)
+	}
+	return b
+}
+
+// testSmall returns a data element in the small data element format.
+func testSmall(typ uint32, data []byte) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, uint32(len(data))<<16|typ)
+	copy(b[4:], data)
+	return b
+}
+
+// testArray returns an miMATRIX element for an array with the given
+// class, dimensions and name, followed by the given subelements.
+func testArray(class Class, dims []int32, name string, sub ...[]byte) []byte {
+	flags := make([]byte, 8)
+	binary.LittleEndian.PutUint32(flags, uint32(class))
+	d := make([]byte, 4*len(dims))
+	for i, v := range dims {
+		binary.LittleEndian.PutUint32(d[4*i:], uint32(v))
+	}
+	data := append(testElement(miUINT32, flags, false), testElement(miINT32, d, false)...)
+	data = append(data, testElement(miINT8, []byte(name), false)...)
+	for _, s := range sub {
+		data = append(data, s...)
+	}
+	return testElement(miMATRIX, data, false)
+}
+
+func doubles(v ...float64) []byte {
+	b := make([]byte, 8*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(f))
+	}
+	return b
+}
+
+func concat(b ...[]byte) []byte {
+	return bytes.Join(b, nil)
+}
+
+func TestReadCompressed(t *testing.T) {
+	x := testArray(DoubleClass, []int32{1, 3}, "x", testElement(miDOUBLE, doubles(1, 2, 3), false))
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	_, err := z.Write(x)
+	if err != nil {
+		t.Fatalf("unexpected error compressing: %v", err)
+	}
+	err = z.Close()
+	if err != nil {
+		t.Fatalf("unexpected error compressing: %v", err)
+	}
+	// Top-level compressed elements are not padded, so the following
+	// element must be found immediately after the compressed data.
+	y := testArray(DoubleClass, []int32{1, 1}, "y", testElement(miDOUBLE, doubles(4), false))
+	b := concat(testHeader(), testElement(miCOMPRESSED, buf.Bytes(), true), y)
+
+	f, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Vars) != 2 {
+		t.Fatalf("unexpected number of variables: got:%d want:2", len(f.Vars))
+	}
+	for _, test := range []struct {
+		name string
+		want []float64
+	}{
+		{name: "x", want: []float64{1, 2, 3}},
+		{name: "y", want: []float64{4}},
+	} {
+		v := f.Var(test.name)
+		if v == nil {
+			t.Errorf("missing variable %s", test.name)
+			continue
+		}
+		if !reflect.DeepEqual(v.Real, test.want) {
+			t.Errorf("unexpected %s: got:%v want:%v", test.name, v.Real, test.want)
+		}
+	}
+}
+
+func TestReadSmallElement(t *testing.T) {
+	// The name and the int16 data fit in small data elements.
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint16(data, 0xfffd) // -3 in two's complement.
+	binary.LittleEndian.PutUint16(data[2:], 7)
+	b := concat(testHeader(), testElement(miMATRIX, concat(
+		testElement(miUINT32, []byte{byte(Int16Class), 0, 0, 0, 0, 0, 0, 0}, false),
+		testElement(miINT32, []byte{1, 0, 0, 0, 2, 0, 0, 0}, false),
+		testSmall(miINT8, []byte("ab")),
+		testSmall(miINT16, data),
+	), false))
+
+	f, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v := f.Var("ab")
+	if v == nil {
+		t.Fatal("missing variable ab")
+	}
+	if v.Class != Int16Class {
+		t.Errorf("unexpected class: got:%v want:%v", v.Class, Int16Class)
+	}
+	want := []float64{-3, 7}
+	if !reflect.DeepEqual(v.Real, want) {
+		t.Errorf("unexpected data: got:%v want:%v", v.Real, want)
+	}
+}
+
+func TestReadCellEmptyElement(t *testing.T) {
+	utf8Char := func(s string) []byte {
+		return testArray(CharClass, []int32{1, int32(len(s))}, "", testElement(miUTF8, []byte(s), false))
+	}
+	b := concat(testHeader(), testArray(CellClass, []int32{1, 3}, "c",
+		utf8Char("ab"),
+		// An empty element is stored with no subelements.
+		testElement(miMATRIX, nil, false),
+		utf8Char("c"),
+	))
+
+	f, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := f.Strings("c")
+	if err != nil {
+		t.Fatalf("unexpected error getting strings: %v", err)
+	}
+	want := []string{"ab", "", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected strings: got:%q want:%q", got, want)
+	}
+}
+
+func TestField(t *testing.T) {
+	fields := make([]byte, 4)
+	binary.LittleEndian.PutUint32(fields, 2)
+	value := func(f float64) []byte {
+		return testArray(DoubleClass, []int32{1, 1}, "", testElement(miDOUBLE, doubles(f), false))
+	}
+	b := concat(testHeader(), testArray(StructClass, []int32{1, 2}, "s",
+		testElement(miINT32, fields, false),
+		testElement(miINT8, []byte("a\x00"), false),
+		value(1),
+		value(2),
+	))
+
+	f, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := f.Var("s")
+	if s == nil {
+		t.Fatal("missing variable s")
+	}
+	for _, test := range []struct {
+		name string
+		i    int
+		want []float64
+	}{
+		{name: "a", i: 0, want: []float64{1}},
+		{name: "a", i: 1, want: []float64{2}},
+		{name: "a", i: 2},
+		{name: "a", i: -1},
+		{name: "b", i: 0},
+	} {
+		got := s.Field(test.name, test.i)
+		if test.want == nil {
+			if got != nil {
+				t.Errorf("unexpected field %s[%d]: got:%v want:nil", test.name, test.i, got.Real)
+			}
+			continue
+		}
+		if got == nil {
+			t.Errorf("missing field %s[%d]", test.name, test.i)
+			continue
+		}
+		if !reflect.DeepEqual(got.Real, test.want) {
+			t.Errorf("unexpected field %s[%d]: got:%v want:%v", test.name, test.i, got.Real, test.want)
+		}
+	}
+}
+
+func TestReadInvalidDims(t *testing.T) {
+	fields := make([]byte, 4)
+	binary.LittleEndian.PutUint32(fields, 2)
+	const big = math.MaxInt32
+	for _, test := range []struct {
+		name string
+		b    []byte
+	}{
+		{
+			name: "negative double",
+			b:    testArray(DoubleClass, []int32{-1, 2}, "x", testElement(miDOUBLE, doubles(1, 2), false)),
+		},
+		{
+			name: "negative cell",
+			b:    testArray(CellClass, []int32{1, -3}, "c"),
+		},
+		{
+			name: "overflowing cell",
+			b:    testArray(CellClass, []int32{big, big, big}, "c"),
+		},
+		{
+			name: "huge cell",
+			b:    testArray(CellClass, []int32{big, big}, "c", testElement(miMATRIX, nil, false)),
+		},
+		{
+			name: "huge struct",
+			b: testArray(StructClass, []int32{1 << 20, 1 << 10}, "s",
+				testElement(miINT32, fields, false),
+				testElement(miINT8, []byte("a\x00"), false),
+				testElement(miMATRIX, nil, false),
+			),
+		},
+	} {
+		_, err := Read(bytes.NewReader(concat(testHeader(), test.b)))
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestReadErrorOffset(t *testing.T) {
+	good := testArray(DoubleClass, []int32{1, 1}, "x", testElement(miDOUBLE, doubles(1), false))
+	bad := testArray(DoubleClass, []int32{-1, 1}, "y", testElement(miDOUBLE, doubles(1), false))
+	_, err := Read(bytes.NewReader(concat(testHeader(), good, bad)))
+	if err == nil {
+		t.Fatal("expected error for invalid dimensions")
+	}
+	want := fmt.Sprintf("element at offset %d:", headerLen+len(good))
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("unexpected error: got:%q want offset in %q", err, want)
+	}
+}
@@ -0,0 +1,369 @@
+package matfile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Writer writes variables to a level 5 MAT-file.
+type Writer struct {
+	w   io.Writer
+	err error
+
+	// Compress specifies whether variables are
+	// written as compressed data elements.
+	Compress bool
+}
+
+// NewWriter returns a new Writer that writes a MAT-file to w.
+// The MAT-file header is written immediately.
+func NewWriter(w io.Writer) (*Writer, error) {
+	hdr := make([]byte, headerLen)
+	text := fmt.Sprintf("MATLAB 5.0 MAT-file, Platform: GLNXA64, Created on: %s", time.Now().Format("Mon Jan _2 15:04:05 2006"))
+	copy(hdr, bytes.Repeat([]byte{' '}, 116))
+	copy(hdr, text)
+	// The subsystem data offset is zero.
+	binary.LittleEndian.PutUint16(hdr[124:], 0x0100)
+	copy(hdr[126:], "IM")
+	_, err := w.Write(hdr)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// Create creates the MAT-file at path and writes the given
+// variables to it.
+func Create(path string, vars ...*Var) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	for _, v := range vars {
+		err = w.WriteVar(v)
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// WriteVar writes v to the MAT-file. Sparse arrays, objects,
+// opaque arrays and function handles are not supported.
+func (w *Writer) WriteVar(v *Var) error {
+	if w.err != nil {
+		return w.err
+	}
+	if v.Name == "" {
+		return errors.New("matfile: variable has no name")
+	}
+	var e encoder
+	err := e.matrix(v)
+	if err != nil {
+		return err
+	}
+	b := e.buf.Bytes()
+	if w.Compress {
+		var buf bytes.Buffer
+		z := zlib.NewWriter(&buf)
+		_, err = z.Write(b)
+		if err != nil {
+			return err
+		}
+		err = z.Close()
+		if err != nil {
+			return err
+		}
+		var c encoder
+		c.tag(miCOMPRESSED, buf.Len())
+		c.buf.Write(buf.Bytes())
+		b = c.buf.Bytes()
+	}
+	_, w.err = w.w.Write(b)
+	return w.err
+}
+
+// WriteMatrix writes m to the MAT-file as a double array with the
+// given name.
+func (w *Writer) WriteMatrix(name string, m mat.Matrix) error {
+	return w.WriteVar(MatrixVar(name, m))
+}
+
+// WriteCMatrix writes m to the MAT-file as a complex double array
+// with the given name.
+func (w *Writer) WriteCMatrix(name string, m mat.CMatrix) error {
+	return w.WriteVar(CMatrixVar(name, m))
+}
+
+// WriteComplex writes z to the MAT-file as a complex double column
+// vector with the given name.
+func (w *Writer) WriteComplex(name string, z []complex128) error {
+	return w.WriteVar(ComplexVar(name, z))
+}
+
+// MatrixVar returns a double array variable holding the values of m.
+func MatrixVar(name string, m mat.Matrix) *Var {
+	r, c := m.Dims()
+	v := &Var{
+		Name:  name,
+		Class: DoubleClass,
+		Dims:  []int{r, c},
+		Real:  make([]float64, r*c),
+	}
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			v.Real[i+j*r] = m.At(i, j)
+		}
+	}
+	return v
+}
+
+// CMatrixVar returns a complex double array variable holding the
+// values of m.
+func CMatrixVar(name string, m mat.CMatrix) *Var {
+	r, c := m.Dims()
+	v := &Var{
+		Name:      name,
+		Class:     DoubleClass,
+		Dims:      []int{r, c},
+		IsComplex: true,
+		Real:      make([]float64, r*c),
+		Imag:      make([]float64, r*c),
+	}
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			z := m.At(i, j)
+			v.Real[i+j*r] = real(z)
+			v.Imag[i+j*r] = imag(z)
+		}
+	}
+	return v
+}
+
+// ComplexVar returns a complex double column vector variable
+// holding the values of z.
+func ComplexVar(name string, z []complex128) *Var {
+	v := &Var{
+		Name:      name,
+		Class:     DoubleClass,
+		Dims:      []int{len(z), 1},
+		IsComplex: true,
+		Real:      make([]float64, len(z)),
+		Imag:      make([]float64, len(z)),
+	}
+	for i, e := range z {
+		v.Real[i] = real(e)
+		v.Imag[i] = imag(e)
+	}
+	return v
+}
+
+// StringVar returns a char array variable holding s as a row vector.
+// Writing the variable will fail if s contains code points above
+// U+FFFF.
+func StringVar(name, s string) *Var {
+	r := []rune(s)
+	v := &Var{
+		Name:  name,
+		Class: CharClass,
+		Dims:  []int{1, len(r)},
+		Real:  make([]float64, len(r)),
+	}
+	for i, c := range r {
+		v.Real[i] = float64(c)
+	}
+	return v
+}
+
+// encoder encodes little-endian MAT-file data elements.
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) tag(typ uint32, n int) {
+	var b [8]byte
+	binary.LittleEndian.PutUint32(b[:], typ)
+	binary.LittleEndian.PutUint32(b[4:], uint32(n))
+	e.buf.Write(b[:])
+}
+
+// element writes a data element with the given type and data,
+// padded to an 8 byte boundary.
+func (e *encoder) element(typ uint32, data []byte) {
+	e.tag(typ, len(data))
+	e.buf.Write(data)
+	e.buf.Write(make([]byte, pad(len(data))))
+}
+
+// matrix writes v as an miMATRIX data element.
+func (e *encoder) matrix(v *Var) error {
+	var sub encoder
+	err := sub.matrixData(v)
+	if err != nil {
+		return err
+	}
+	e.element(miMATRIX, sub.buf.Bytes())
+	return nil
+}
+
+func (e *encoder) matrixData(v *Var) error {
+	typ, ok := classType[v.Class]
+	if !ok && v.Class != CellClass && v.Class != StructClass {
+		return fmt.Errorf("matfile: %s: unsupported class for writing: %v", v.name(), v.Class)
+	}
+	if len(v.Dims) < 2 {
+		return fmt.Errorf("matfile: %s: invalid dimensions: %v", v.name(), v.Dims)
+	}
+
+	flags := uint32(v.Class)
+	if v.IsComplex {
+		flags |= flagComplex << 8
+	}
+	if v.IsGlobal {
+		flags |= flagGlobal << 8
+	}
+	if v.IsLogical {
+		flags |= flagLogical << 8
+	}
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, flags)
+	e.element(miUINT32, data)
+
+	data = make([]byte, 4*len(v.Dims))
+	for i, d := range v.Dims {
+		binary.LittleEndian.PutUint32(data[4*i:], uint32(d))
+	}
+	e.element(miINT32, data)
+
+	e.element(miINT8, []byte(v.Name))
+
+	switch v.Class {
+	case CellClass:
+		if len(v.Cells) != v.Len() {
+			return fmt.Errorf("matfile: %s: cell count does not match dimensions %v", v.name(), v.Dims)
+		}
+		for _, c := range v.Cells {
+			err := e.matrix(c)
+			if err != nil {
+				return err
+			}
+		}
+	case StructClass:
+		if len(v.Values) != v.Len()*len(v.Fields) {
+			return fmt.Errorf("matfile: %s: field value count does not match dimensions %v", v.name(), v.Dims)
+		}
+		width := 1
+		for _, f := range v.Fields {
+			if len(f)+1 > width {
+				width = len(f) + 1
+			}
+		}
+		data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(width))
+		e.element(miINT32, data)
+		data = make([]byte, width*len(v.Fields))
+		for i, f := range v.Fields {
+			copy(data[i*width:], f)
+		}
+		e.element(miINT8, data)
+		for _, f := range v.Values {
+			err := e.matrix(f)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if len(v.Real) != v.Len() || (v.IsComplex && len(v.Imag) != v.Len()) {
+			return fmt.Errorf("matfile: %s: data length does not match dimensions %v", v.name(), v.Dims)
+		}
+		if v.Class == CharClass {
+			// MATLAB chars are UTF-16 code units, so code points
+			// outside the basic multilingual plane cannot be held
+			// in a single element.
+			for _, c := range v.Real {
+				if c < 0 || c > 0xffff || c != math.Trunc(c) {
+					return fmt.Errorf("matfile: %s: invalid char value: %v", v.name(), c)
+				}
+			}
+		}
+		e.element(typ, numericBytes(typ, v.Real))
+		if v.IsComplex {
+			e.element(typ, numericBytes(typ, v.Imag))
+		}
+	}
+	return nil
+}
+
+// classType is the data type used to store each writable class.
+var classType = map[Class]uint32{
+	CharClass:   miUINT16,
+	DoubleClass: miDOUBLE,
+	SingleClass: miSINGLE,
+	Int8Class:   miINT8,
+	Uint8Class:  miUINT8,
+	Int16Class:  miINT16,
+	Uint16Class: miUINT16,
+	Int32Class:  miINT32,
+	Uint32Class: miUINT32,
+	Int64Class:  miINT64,
+	Uint64Class: miUINT64,
+}
+
+// numericBytes returns the little-endian encoding of v as the given type.
+func numericBytes(typ uint32, v []float64) []byte {
+	var size int
+	switch typ {
+	case miINT8, miUINT8:
+		size = 1
+	case miINT16, miUINT16:
+		size = 2
+	case miINT32, miUINT32, miSINGLE:
+		size = 4
+	case miDOUBLE, miINT64, miUINT64:
+		size = 8
+	default:
+		panic("matfile: invalid numeric type")
+	}
+	b := make([]byte, size*len(v))
+	for i, f := range v {
+		d := b[i*size:]
+		switch typ {
+		case miINT8:
+			d[0] = byte(int8(f))
+		case miUINT8:
+			d[0] = byte(f)
+		case miINT16:
+			binary.LittleEndian.PutUint16(d, uint16(int16(f)))
+		case miUINT16:
+			binary.LittleEndian.PutUint16(d, uint16(f))
+		case miINT32:
+			binary.LittleEndian.PutUint32(d, uint32(int32(f)))
+		case miUINT32:
+			binary.LittleEndian.PutUint32(d, uint32(f))
+		case miSINGLE:
+			binary.LittleEndian.PutUint32(d, math.Float32bits(float32(f)))
+		case miDOUBLE:
+			binary.LittleEndian.PutUint64(d, math.Float64bits(f))
+		case miINT64:
+			binary.LittleEndian.PutUint64(d, uint64(int64(f)))
+		case miUINT64:
+			binary.LittleEndian.PutUint64(d, uint64(f))
+		}
+	}
+	return b
+}
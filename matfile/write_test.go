@@ -0,0 +1,170 @@
+package matfile
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRoundTripData(t *testing.T) {
+	paths, err := filepath.Glob(filepath.FromSlash("../DATA/*.mat"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no MAT-files found")
+	}
+	for _, path := range paths {
+		want, err := Open(path)
+		if err != nil {
+			t.Errorf("unexpected error reading %s: %v", path, err)
+			continue
+		}
+		for _, compress := range []bool{false, true} {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error creating writer: %v", err)
+			}
+			w.Compress = compress
+			var written []*Var
+			for _, v := range want.Vars {
+				if v.Class == OpaqueClass {
+					// Opaque arrays are not decoded so cannot be written.
+					continue
+				}
+				err = w.WriteVar(v)
+				if err != nil {
+					t.Errorf("unexpected error writing %s from %s: %v", v.Name, path, err)
+					continue
+				}
+				written = append(written, v)
+			}
+
+			got, err := Read(&buf)
+			if err != nil {
+				t.Errorf("unexpected error reading round trip of %s compress=%t: %v", path, compress, err)
+				continue
+			}
+			if !reflect.DeepEqual(got.Vars, written) {
+				t.Errorf("round trip mismatch for %s compress=%t", path, compress)
+			}
+		}
+	}
+}
+
+func TestWriteMatrices(t *testing.T) {
+	dense := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	vec := mat.NewVecDense(4, []float64{-1, 0.5, 1e300, 7})
+	z := []complex128{1 + 2i, -3i, 4}
+	cdense := mat.NewCDense(2, 2, []complex128{1, 2i, 3 + 3i, -4})
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+	for _, fn := range []func() error{
+		func() error { return w.WriteMatrix("dense", dense) },
+		func() error { return w.WriteMatrix("vec", vec) },
+		func() error { return w.WriteComplex("z", z) },
+		func() error { return w.WriteCMatrix("cdense", cdense) },
+		func() error { return w.WriteVar(StringVar("s", "hello, world")) },
+	} {
+		err = fn()
+		if err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+
+	f, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+
+	gotDense, err := f.Dense("dense")
+	if err != nil {
+		t.Fatalf("unexpected error getting dense: %v", err)
+	}
+	if !mat.Equal(gotDense, dense) {
+		t.Errorf("unexpected dense:\ngot: %v\nwant:%v", mat.Formatted(gotDense), mat.Formatted(dense))
+	}
+
+	gotVec, err := f.Dense("vec")
+	if err != nil {
+		t.Fatalf("unexpected error getting vec: %v", err)
+	}
+	if !mat.Equal(gotVec, vec) {
+		t.Errorf("unexpected vec:\ngot: %v\nwant:%v", mat.Formatted(gotVec), mat.Formatted(vec))
+	}
+
+	gotZ, err := f.CDense("z")
+	if err != nil {
+		t.Fatalf("unexpected error getting z: %v", err)
+	}
+	if r, c := gotZ.Dims(); r != len(z) || c != 1 {
+		t.Fatalf("unexpected z dimensions: got:%d×%d want:%d×1", r, c, len(z))
+	}
+	for i, want := range z {
+		if got := gotZ.At(i, 0); got != want {
+			t.Errorf("unexpected z[%d]: got:%v want:%v", i, got, want)
+		}
+	}
+
+	gotCDense, err := f.CDense("cdense")
+	if err != nil {
+		t.Fatalf("unexpected error getting cdense: %v", err)
+	}
+	if !mat.CEqual(gotCDense, cdense) {
+		t.Errorf("unexpected cdense:\ngot: %v\nwant:%v", gotCDense, cdense)
+	}
+
+	gotS, err := f.Strings("s")
+	if err != nil {
+		t.Fatalf("unexpected error getting s: %v", err)
+	}
+	if len(gotS) != 1 || gotS[0] != "hello, world" {
+		t.Errorf("unexpected s: got:%q want:%q", gotS, []string{"hello, world"})
+	}
+}
+
+func TestWriteCharRange(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		wantErr bool
+	}{
+		{s: "hello"},
+		{s: "héllo, wörld"},
+		{s: "\uffff"},
+		{s: "\U00010000", wantErr: true},
+		{s: "a😀b", wantErr: true},
+	} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error creating writer: %v", err)
+		}
+		err = w.WriteVar(StringVar("s", test.s))
+		if (err != nil) != test.wantErr {
+			t.Errorf("unexpected error for %q: got:%v want error:%t", test.s, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		f, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error reading: %v", err)
+		}
+		got, err := f.Strings("s")
+		if err != nil {
+			t.Fatalf("unexpected error getting s: %v", err)
+		}
+		if len(got) != 1 || got[0] != test.s {
+			t.Errorf("unexpected s: got:%q want:%q", got, []string{test.s})
+		}
+	}
+}
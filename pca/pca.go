@@ -0,0 +1,149 @@
+// Package pca provides principal component analysis based on the singular
+// value decomposition.
+package pca
+
+import (
+	"errors"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// ErrFactorize is returned when a singular value decomposition fails.
+var ErrFactorize = errors.New("pca: failed to factorize matrix")
+
+// ErrTooFew is returned when fewer than two observations are provided.
+var ErrTooFew = errors.New("pca: fewer than two observations")
+
+// PCA is a principal component analysis of a set of observations.
+type PCA struct {
+	// Mean holds the column means subtracted
+	// from the observations before analysis.
+	// Mean is nil if the observations were not
+	// centered.
+	Mean []float64
+
+	// Loadings holds the principal directions,
+	// the right singular vectors of the
+	// observations, in its columns.
+	Loadings *mat.Dense
+
+	// Sigma holds the singular values of the
+	// observations in descending order.
+	Sigma []float64
+
+	// n is the number of observations.
+	n int
+}
+
+// Fit returns the principal component analysis of the observations held
+// in the rows of x. If center is true the column means are subtracted from
+// x before factorization, giving the conventional principal components of
+// the data covariance. Otherwise x is factorized directly, as the book
+// does for the ovarian cancer data in CH01_SEC05_1_OvarianCancer. Fit
+// returns ErrTooFew if x has fewer than two rows, since the variance is
+// then undefined.
+func Fit(x mat.Matrix, center bool) (*PCA, error) {
+	r, c := x.Dims()
+	if r < 2 {
+		return nil, ErrTooFew
+	}
+	var p PCA
+	p.n = r
+	if center {
+		p.Mean = make([]float64, c)
+		col := make([]float64, r)
+		for j := range p.Mean {
+			mat.Col(col, j, x)
+			p.Mean[j] = stat.Mean(col, nil)
+		}
+		x = p.centered(x)
+	}
+
+	var svd mat.SVD
+	ok := svd.Factorize(x, mat.SVDThin)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	p.Loadings = &mat.Dense{}
+	svd.VTo(p.Loadings)
+	p.Sigma = svd.Values(nil)
+	return &p, nil
+}
+
+// Len returns the number of principal components.
+func (p *PCA) Len() int {
+	return len(p.Sigma)
+}
+
+// Variance returns the variance of the observations along each principal
+// direction, σᵢ²/(n-1) for n observations.
+func (p *PCA) Variance() []float64 {
+	v := make([]float64, len(p.Sigma))
+	for i, s := range p.Sigma {
+		v[i] = s * s / float64(p.n-1)
+	}
+	return v
+}
+
+// ExplainedRatio returns the fraction of the total variance of the
+// observations explained by each principal component.
+func (p *PCA) ExplainedRatio() []float64 {
+	v := p.Variance()
+	floats.Scale(1/floats.Sum(v), v)
+	return v
+}
+
+// Cumulative returns the cumulative fraction of the total variance of the
+// observations explained by the leading principal components.
+func (p *PCA) Cumulative() []float64 {
+	v := p.ExplainedRatio()
+	floats.CumSum(v, v)
+	return v
+}
+
+// Scores places the projection of the observations in the rows of x onto
+// the first k principal directions into dst. If the analysis was centered,
+// the fitted means are subtracted from x before projection. If k is greater
+// than the number of principal components, all of them are used.
+func (p *PCA) Scores(dst *mat.Dense, x mat.Matrix, k int) {
+	if k > len(p.Sigma) {
+		k = len(p.Sigma)
+	}
+	if p.Mean != nil {
+		x = p.centered(x)
+	}
+	c, _ := p.Loadings.Dims()
+	dst.Reset()
+	dst.Mul(x, p.Loadings.Slice(0, c, 0, k))
+}
+
+// Reconstruct places the reconstruction of observations from their scores
+// on the leading principal directions into dst. The number of directions
+// used is the number of columns in scores.
+func (p *PCA) Reconstruct(dst *mat.Dense, scores mat.Matrix) {
+	_, k := scores.Dims()
+	c, _ := p.Loadings.Dims()
+	dst.Reset()
+	dst.Mul(scores, p.Loadings.Slice(0, c, 0, k).T())
+	if p.Mean == nil {
+		return
+	}
+	r, _ := dst.Dims()
+	for i := 0; i < r; i++ {
+		floats.Add(dst.RawRowView(i), p.Mean)
+	}
+}
+
+func (p *PCA) centered(x mat.Matrix) *mat.Dense {
+	r, c := x.Dims()
+	if c != len(p.Mean) {
+		panic("pca: column count mismatch")
+	}
+	d := mat.DenseCopyOf(x)
+	for i := 0; i < r; i++ {
+		floats.Sub(d.RawRowView(i), p.Mean)
+	}
+	return d
+}
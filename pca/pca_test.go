@@ -0,0 +1,191 @@
+package pca
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// testData returns a 40×4 matrix of correlated observations with
+// non-zero column means.
+func testData(rnd *rand.Rand) *mat.Dense {
+	x := mat.NewDense(40, 4, nil)
+	for i := 0; i < 40; i++ {
+		a, b, c, d := rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()
+		x.SetRow(i, []float64{5 + 3*a, -2 + a + b, 1 + 0.5*c - b, 0.1 * d})
+	}
+	return x
+}
+
+func TestFit(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	r, c := x.Dims()
+
+	for _, center := range []bool{true, false} {
+		p, err := Fit(x, center)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Len() != c {
+			t.Errorf("unexpected number of components: got:%d want:%d", p.Len(), c)
+		}
+
+		// Compare with an independent factorization of the
+		// (centered) observations.
+		a := mat.DenseCopyOf(x)
+		if center {
+			col := make([]float64, r)
+			for j := 0; j < c; j++ {
+				mat.Col(col, j, x)
+				mean := stat.Mean(col, nil)
+				if !scalar.EqualWithinAbsOrRel(p.Mean[j], mean, 1e-12, 1e-12) {
+					t.Errorf("unexpected mean %d: got:%v want:%v", j, p.Mean[j], mean)
+				}
+				floats.AddConst(-mean, col)
+				a.SetCol(j, col)
+			}
+		} else if p.Mean != nil {
+			t.Error("unexpected mean for uncentered analysis")
+		}
+		var svd mat.SVD
+		if !svd.Factorize(a, mat.SVDThin) {
+			t.Fatal("failed to factorize")
+		}
+		var u, v mat.Dense
+		svd.UTo(&u)
+		svd.VTo(&v)
+		sigma := svd.Values(nil)
+		if !floats.EqualApprox(p.Sigma, sigma, 1e-12) {
+			t.Errorf("unexpected singular values for center=%t: got:%v want:%v", center, p.Sigma, sigma)
+		}
+
+		var scores mat.Dense
+		p.Scores(&scores, x, c)
+		got := make([]float64, c)
+		want := make([]float64, c)
+		for j := 0; j < c; j++ {
+			// Singular vectors are determined up to sign.
+			mat.Col(got, j, p.Loadings)
+			mat.Col(want, j, &v)
+			if !equalSign(got, want, 1e-12) {
+				t.Errorf("unexpected loading %d for center=%t: got:%v want:%v", j, center, got, want)
+			}
+			// The scores are UΣ.
+			gotScores := mat.Col(nil, j, &scores)
+			wantScores := mat.Col(nil, j, &u)
+			floats.Scale(sigma[j], wantScores)
+			if !equalSign(gotScores, wantScores, 1e-10) {
+				t.Errorf("unexpected scores %d for center=%t", j, center)
+			}
+		}
+
+		// Reconstruction from all components recovers the data.
+		var back mat.Dense
+		p.Reconstruct(&back, &scores)
+		if !mat.EqualApprox(&back, x, 1e-10) {
+			t.Errorf("unexpected full reconstruction for center=%t", center)
+		}
+	}
+}
+
+func TestVariance(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	_, c := x.Dims()
+	p, err := Fit(x, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The component variances are the eigenvalues of the sample covariance.
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, x, nil)
+	var eig mat.EigenSym
+	if !eig.Factorize(&cov, false) {
+		t.Fatal("failed to factorize covariance")
+	}
+	want := eig.Values(nil)
+	floats.Reverse(want)
+	got := p.Variance()
+	if !floats.EqualApprox(got, want, 1e-10) {
+		t.Errorf("unexpected variance: got:%v want:%v", got, want)
+	}
+
+	ratio := p.ExplainedRatio()
+	cum := p.Cumulative()
+	total := floats.Sum(want)
+	var sum float64
+	for i := range ratio {
+		sum += want[i] / total
+		if !scalar.EqualWithinAbsOrRel(ratio[i], want[i]/total, 1e-12, 1e-12) {
+			t.Errorf("unexpected explained ratio %d: got:%v want:%v", i, ratio[i], want[i]/total)
+		}
+		if !scalar.EqualWithinAbsOrRel(cum[i], sum, 1e-12, 1e-12) {
+			t.Errorf("unexpected cumulative ratio %d: got:%v want:%v", i, cum[i], sum)
+		}
+	}
+	if !scalar.EqualWithinAbs(cum[c-1], 1, 1e-12) {
+		t.Errorf("cumulative ratio does not reach one: %v", cum[c-1])
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	r, _ := x.Dims()
+	p, err := Fit(x, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for k := 1; k <= p.Len(); k++ {
+		var scores, back, diff mat.Dense
+		p.Scores(&scores, x, k)
+		if _, sc := scores.Dims(); sc != k {
+			t.Errorf("unexpected number of scores: got:%d want:%d", sc, k)
+		}
+		p.Reconstruct(&back, &scores)
+		diff.Sub(x, &back)
+
+		// The residual sum of squares is the sum of the discarded σᵢ².
+		got := mat.Norm(&diff, 2)
+		var want float64
+		for _, s := range p.Sigma[k:] {
+			want += s * s
+		}
+		want = math.Sqrt(want)
+		if !scalar.EqualWithinAbsOrRel(got, want, 1e-10, 1e-10) {
+			t.Errorf("unexpected residual for k=%d: got:%v want:%v", k, got, want)
+		}
+	}
+	var scores mat.Dense
+	p.Scores(&scores, x, p.Len()+3)
+	if sr, sc := scores.Dims(); sr != r || sc != p.Len() {
+		t.Errorf("unexpected dimensions for large k: got:%d×%d want:%d×%d", sr, sc, r, p.Len())
+	}
+}
+
+func TestTooFew(t *testing.T) {
+	for _, center := range []bool{true, false} {
+		_, err := Fit(mat.NewDense(1, 3, []float64{1, 2, 3}), center)
+		if err != ErrTooFew {
+			t.Errorf("unexpected error for center=%t: got:%v want:%v", center, err, ErrTooFew)
+		}
+	}
+}
+
+// equalSign returns whether a and b are equal within tol, or equal within
+// tol after negation of b.
+func equalSign(a, b []float64, tol float64) bool {
+	if floats.EqualApprox(a, b, tol) {
+		return true
+	}
+	neg := make([]float64, len(b))
+	floats.ScaleTo(neg, -1, b)
+	return floats.EqualApprox(a, neg, tol)
+}
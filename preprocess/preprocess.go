@@ -0,0 +1,312 @@
+// Package preprocess provides column transformations for preparing data
+// matrices for model fitting. Each transformation is fitted to a set of
+// observations held in the rows of a matrix and may then be applied to
+// the same or new observations.
+package preprocess
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// ErrFactorize is returned when the covariance of the data cannot be
+// factorized.
+var ErrFactorize = errors.New("preprocess: failed to factorize covariance")
+
+// Transformer is a column transformation with parameters fitted to data.
+type Transformer interface {
+	// Fit fits the transformation parameters to
+	// the observations in the rows of x.
+	Fit(x mat.Matrix) error
+
+	// Transform places the transformation of x
+	// into dst.
+	Transform(dst *mat.Dense, x mat.Matrix)
+
+	// Inverse places the inverse transformation
+	// of z into dst.
+	Inverse(dst *mat.Dense, z mat.Matrix)
+}
+
+var (
+	_ Transformer = (*Center)(nil)
+	_ Transformer = (*ZScore)(nil)
+	_ Transformer = (*MinMax)(nil)
+	_ Transformer = (*Whiten)(nil)
+	_ Transformer = Intercept{}
+)
+
+// Affine is a column-wise affine transformation, z_j = (x_j - Shift_j) / Scale_j.
+// A nil Shift or Scale is treated as all zeros or all ones respectively.
+type Affine struct {
+	Shift, Scale []float64
+}
+
+// Transform places the transformation of x into dst.
+func (a *Affine) Transform(dst *mat.Dense, x mat.Matrix) {
+	a.checkCols(x)
+	dst.CloneFrom(x)
+	r, c := dst.Dims()
+	for j := 0; j < c; j++ {
+		shift, scale := a.at(j)
+		for i := 0; i < r; i++ {
+			dst.Set(i, j, (dst.At(i, j)-shift)/scale)
+		}
+	}
+}
+
+// Inverse places the inverse transformation of z into dst.
+func (a *Affine) Inverse(dst *mat.Dense, z mat.Matrix) {
+	a.checkCols(z)
+	dst.CloneFrom(z)
+	r, c := dst.Dims()
+	for j := 0; j < c; j++ {
+		shift, scale := a.at(j)
+		for i := 0; i < r; i++ {
+			dst.Set(i, j, dst.At(i, j)*scale+shift)
+		}
+	}
+}
+
+// Coefficients returns the coefficients and intercept of a linear model
+// in the original units of the data given the coefficients, beta, and
+// intercept of the model fitted to the transformed data.
+func (a *Affine) Coefficients(beta []float64, intercept float64) ([]float64, float64) {
+	if a.Shift != nil && len(beta) != len(a.Shift) || a.Scale != nil && len(beta) != len(a.Scale) {
+		panic("preprocess: coefficient length mismatch")
+	}
+	orig := make([]float64, len(beta))
+	for j, b := range beta {
+		shift, scale := a.at(j)
+		orig[j] = b / scale
+		intercept -= orig[j] * shift
+	}
+	return orig, intercept
+}
+
+func (a *Affine) at(j int) (shift, scale float64) {
+	shift, scale = 0, 1
+	if a.Shift != nil {
+		shift = a.Shift[j]
+	}
+	if a.Scale != nil {
+		scale = a.Scale[j]
+	}
+	return shift, scale
+}
+
+func (a *Affine) checkCols(x mat.Matrix) {
+	_, c := x.Dims()
+	if a.Shift != nil && c != len(a.Shift) || a.Scale != nil && c != len(a.Scale) {
+		panic("preprocess: column count mismatch")
+	}
+}
+
+// Center is a transformation that subtracts the column means.
+type Center struct {
+	Affine
+}
+
+// Fit sets the shift of c to the column means of x.
+func (c *Center) Fit(x mat.Matrix) error {
+	c.Shift = colMeans(x)
+	c.Scale = nil
+	return nil
+}
+
+// ZScore is a transformation that subtracts the column means and divides
+// by the column sample standard deviations, as done by MATLAB's zscore.
+type ZScore struct {
+	Affine
+}
+
+// Fit sets the shift and scale of z to the column means and sample
+// standard deviations of x. Columns with zero standard deviation are
+// given a scale of one.
+func (z *ZScore) Fit(x mat.Matrix) error {
+	r, c := x.Dims()
+	z.Shift = make([]float64, c)
+	z.Scale = make([]float64, c)
+	col := make([]float64, r)
+	for j := 0; j < c; j++ {
+		mat.Col(col, j, x)
+		z.Shift[j], z.Scale[j] = stat.MeanStdDev(col, nil)
+		if z.Scale[j] == 0 {
+			z.Scale[j] = 1
+		}
+	}
+	return nil
+}
+
+// MinMax is a transformation that maps each column onto the interval
+// [0, 1] using its minimum and maximum.
+type MinMax struct {
+	Affine
+}
+
+// Fit sets the shift and scale of m to the column minimums and ranges
+// of x. Columns with zero range are given a scale of one.
+func (m *MinMax) Fit(x mat.Matrix) error {
+	r, c := x.Dims()
+	m.Shift = make([]float64, c)
+	m.Scale = make([]float64, c)
+	for j := 0; j < c; j++ {
+		min, max := math.Inf(1), math.Inf(-1)
+		for i := 0; i < r; i++ {
+			v := x.At(i, j)
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+		m.Shift[j] = min
+		m.Scale[j] = max - min
+		if m.Scale[j] == 0 {
+			m.Scale[j] = 1
+		}
+	}
+	return nil
+}
+
+// Whiten is a zero-phase (ZCA) whitening transformation that centers the
+// columns and decorrelates them to unit variance while keeping the
+// transformed data as close as possible to the original, z = (x - μ) W
+// with W = V Λ^-½ Vᵀ, where V Λ Vᵀ is the eigendecomposition of the
+// sample covariance of x.
+type Whiten struct {
+	// Epsilon is added to the covariance
+	// eigenvalues to regularize the whitening
+	// of low variance directions.
+	Epsilon float64
+
+	// Mean holds the fitted column means.
+	Mean []float64
+
+	// W and WInv hold the fitted whitening
+	// matrix and its inverse.
+	W, WInv *mat.Dense
+}
+
+// Fit fits the whitening transformation to the observations in the rows
+// of x. Directions with regularized variance that is zero to within
+// rounding error are projected out, so rank-deficient data may be
+// whitened. Fit returns ErrFactorize if x has fewer than two rows or no
+// columns, or the covariance of x is not finite or cannot be factorized.
+func (w *Whiten) Fit(x mat.Matrix) error {
+	r, c := x.Dims()
+	if r < 2 || c == 0 {
+		return ErrFactorize
+	}
+
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, x, nil)
+	var eig mat.EigenSym
+	ok := eig.Factorize(&cov, true)
+	if !ok {
+		return ErrFactorize
+	}
+	var v mat.Dense
+	eig.VectorsTo(&v)
+	lambda := eig.Values(nil)
+	for _, l := range lambda {
+		if math.IsNaN(l) || math.IsInf(l, 0) {
+			return ErrFactorize
+		}
+	}
+	// The eigenvalues are in ascending order.
+	tol := float64(c) * math.Abs(lambda[c-1]+w.Epsilon) * eps
+	inv := make([]float64, c)
+	sqrt := make([]float64, c)
+	for i, l := range lambda {
+		l += w.Epsilon
+		if l <= tol {
+			continue
+		}
+		sqrt[i] = math.Sqrt(l)
+		inv[i] = 1 / sqrt[i]
+	}
+
+	var vd mat.Dense
+	w.W = &mat.Dense{}
+	vd.Mul(&v, mat.NewDiagDense(c, inv))
+	w.W.Mul(&vd, v.T())
+	w.WInv = &mat.Dense{}
+	vd.Mul(&v, mat.NewDiagDense(c, sqrt))
+	w.WInv.Mul(&vd, v.T())
+	w.Mean = colMeans(x)
+	return nil
+}
+
+// eps is the machine epsilon for float64.
+const eps = 0x1p-52
+
+// Transform places the whitened x into dst.
+func (w *Whiten) Transform(dst *mat.Dense, x mat.Matrix) {
+	center := Center{Affine{Shift: w.Mean}}
+	var t mat.Dense
+	center.Transform(&t, x)
+	dst.Reset()
+	dst.Mul(&t, w.W)
+}
+
+// Inverse places the unwhitened z into dst.
+func (w *Whiten) Inverse(dst *mat.Dense, z mat.Matrix) {
+	var t mat.Dense
+	t.Mul(z, w.WInv)
+	center := Center{Affine{Shift: w.Mean}}
+	center.Inverse(dst, &t)
+}
+
+// Coefficients returns the coefficients and intercept of a linear model
+// in the original units of the data given the coefficients, beta, and
+// intercept of the model fitted to the whitened data.
+func (w *Whiten) Coefficients(beta []float64, intercept float64) ([]float64, float64) {
+	if len(beta) != len(w.Mean) {
+		panic("preprocess: coefficient length mismatch")
+	}
+	orig := mat.NewVecDense(len(beta), nil)
+	orig.MulVec(w.W, mat.NewVecDense(len(beta), beta))
+	intercept -= mat.Dot(orig, mat.NewVecDense(len(w.Mean), w.Mean))
+	return orig.RawVector().Data, intercept
+}
+
+// Intercept is a transformation that appends a column of ones to allow
+// a linear model to fit a constant offset. It has no fitted parameters.
+type Intercept struct{}
+
+// Fit is a no-op.
+func (Intercept) Fit(mat.Matrix) error { return nil }
+
+// Transform places x with an additional final column of ones into dst.
+func (Intercept) Transform(dst *mat.Dense, x mat.Matrix) {
+	r, c := x.Dims()
+	t := mat.NewDense(r, c+1, nil)
+	t.Slice(0, r, 0, c).(*mat.Dense).Copy(x)
+	for i := 0; i < r; i++ {
+		t.Set(i, c, 1)
+	}
+	dst.CloneFrom(t)
+}
+
+// Inverse places z without its final column into dst.
+func (Intercept) Inverse(dst *mat.Dense, z mat.Matrix) {
+	r, c := z.Dims()
+	if c == 0 {
+		panic("preprocess: no intercept column")
+	}
+	t := mat.NewDense(r, c-1, nil)
+	t.Copy(z)
+	dst.CloneFrom(t)
+}
+
+func colMeans(x mat.Matrix) []float64 {
+	r, c := x.Dims()
+	mean := make([]float64, c)
+	col := make([]float64, r)
+	for j := range mean {
+		mat.Col(col, j, x)
+		mean[j] = stat.Mean(col, nil)
+	}
+	return mean
+}
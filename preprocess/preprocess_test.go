@@ -0,0 +1,193 @@
+package preprocess
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// testData returns a 50×3 matrix of correlated observations with
+// distinct column means and scales.
+func testData(rnd *rand.Rand) *mat.Dense {
+	x := mat.NewDense(50, 3, nil)
+	for i := 0; i < 50; i++ {
+		a, b, c := rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()
+		x.SetRow(i, []float64{10 + 2*a, -5 + a + 0.5*b, 100 * (c - 0.3*a)})
+	}
+	return x
+}
+
+func TestRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	deficient := mat.DenseCopyOf(x)
+	// Make the last column a combination of the first two.
+	for i := 0; i < 50; i++ {
+		deficient.Set(i, 2, x.At(i, 0)-3*x.At(i, 1))
+	}
+	for _, test := range []struct {
+		name string
+		tr   Transformer
+		x    *mat.Dense
+	}{
+		{name: "center", tr: &Center{}, x: x},
+		{name: "zscore", tr: &ZScore{}, x: x},
+		{name: "minmax", tr: &MinMax{}, x: x},
+		{name: "whiten", tr: &Whiten{}, x: x},
+		{name: "regularized whiten", tr: &Whiten{Epsilon: 0.1}, x: x},
+		{name: "rank-deficient whiten", tr: &Whiten{}, x: deficient},
+		{name: "intercept", tr: Intercept{}, x: x},
+	} {
+		err := test.tr.Fit(test.x)
+		if err != nil {
+			t.Errorf("unexpected error fitting %s: %v", test.name, err)
+			continue
+		}
+		var z, back mat.Dense
+		test.tr.Transform(&z, test.x)
+		test.tr.Inverse(&back, &z)
+		if !mat.EqualApprox(&back, test.x, 1e-10) {
+			t.Errorf("round trip mismatch for %s", test.name)
+		}
+
+		// Transformations also apply to new data.
+		y := testData(rnd)
+		test.tr.Transform(&z, y)
+		test.tr.Inverse(&back, &z)
+		if test.name != "rank-deficient whiten" && !mat.EqualApprox(&back, y, 1e-10) {
+			t.Errorf("round trip mismatch for new data with %s", test.name)
+		}
+	}
+}
+
+func TestTransformProperties(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	_, c := x.Dims()
+	col := make([]float64, 50)
+
+	var z mat.Dense
+	var zs ZScore
+	err := zs.Fit(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zs.Transform(&z, x)
+	for j := 0; j < c; j++ {
+		mat.Col(col, j, &z)
+		mean, std := stat.MeanStdDev(col, nil)
+		if !scalar.EqualWithinAbs(mean, 0, 1e-12) || !scalar.EqualWithinAbs(std, 1, 1e-12) {
+			t.Errorf("unexpected z-score column %d: mean=%v std=%v", j, mean, std)
+		}
+	}
+
+	var mm MinMax
+	err = mm.Fit(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mm.Transform(&z, x)
+	for j := 0; j < c; j++ {
+		mat.Col(col, j, &z)
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, v := range col {
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+		if !scalar.EqualWithinAbs(min, 0, 1e-14) || !scalar.EqualWithinAbs(max, 1, 1e-14) {
+			t.Errorf("unexpected min-max column %d range: [%v, %v]", j, min, max)
+		}
+	}
+
+	var w Whiten
+	err = w.Fit(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Transform(&z, x)
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, &z, nil)
+	if !mat.EqualApprox(&cov, eye(c), 1e-10) {
+		t.Errorf("whitened covariance is not the identity:\n%v", mat.Formatted(&cov))
+	}
+	// ZCA whitening has a symmetric whitening matrix.
+	if !mat.EqualApprox(w.W, w.W.T(), 1e-12) {
+		t.Error("whitening matrix is not symmetric")
+	}
+}
+
+func TestWhitenError(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		x    mat.Matrix
+	}{
+		{name: "single row", x: mat.NewDense(1, 3, []float64{1, 2, 3})},
+		{name: "NaN", x: mat.NewDense(3, 2, []float64{1, 2, math.NaN(), 4, 5, 6})},
+		{name: "no columns", x: noCols(3)},
+		{name: "empty", x: &mat.Dense{}},
+	} {
+		var w Whiten
+		err := w.Fit(test.x)
+		if err != ErrFactorize {
+			t.Errorf("unexpected error for %s: got:%v want:%v", test.name, err, ErrFactorize)
+		}
+	}
+}
+
+// noCols is a matrix with rows but no columns.
+type noCols int
+
+func (m noCols) Dims() (r, c int)    { return int(m), 0 }
+func (m noCols) At(i, j int) float64 { panic(mat.ErrIndexOutOfRange) }
+func (m noCols) T() mat.Matrix       { return mat.Transpose{Matrix: m} }
+
+func TestCoefficients(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := testData(rnd)
+	beta := []float64{1.5, -2, 0.01}
+	const intercept = 3.0
+
+	// A linear model in the transformed units gives the same predictions
+	// as the returned model in the original units.
+	for _, test := range []struct {
+		name string
+		tr   interface {
+			Transformer
+			Coefficients(beta []float64, intercept float64) ([]float64, float64)
+		}
+	}{
+		{name: "center", tr: &Center{}},
+		{name: "zscore", tr: &ZScore{}},
+		{name: "minmax", tr: &MinMax{}},
+		{name: "whiten", tr: &Whiten{}},
+	} {
+		err := test.tr.Fit(x)
+		if err != nil {
+			t.Errorf("unexpected error fitting %s: %v", test.name, err)
+			continue
+		}
+		var z mat.Dense
+		test.tr.Transform(&z, x)
+		orig, origIntercept := test.tr.Coefficients(beta, intercept)
+		for i := 0; i < 50; i++ {
+			want := intercept + mat.Dot(z.RowView(i), mat.NewVecDense(3, beta))
+			got := origIntercept + mat.Dot(x.RowView(i), mat.NewVecDense(3, orig))
+			if !scalar.EqualWithinAbsOrRel(got, want, 1e-10, 1e-10) {
+				t.Errorf("unexpected prediction %d for %s: got:%v want:%v", i, test.name, got, want)
+				break
+			}
+		}
+	}
+}
+
+func eye(n int) *mat.DiagDense {
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = 1
+	}
+	return mat.NewDiagDense(n, d)
+}
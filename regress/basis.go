@@ -0,0 +1,83 @@
+package regress
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Basis is a set of basis functions of a scalar predictor.
+type Basis interface {
+	// Len returns the number of basis functions.
+	Len() int
+
+	// Eval places the values of the basis
+	// functions at x into dst.
+	Eval(dst []float64, x float64)
+}
+
+// Polynomial is the monomial basis 1, x, x², …, xⁿ for the polynomial
+// degree n.
+type Polynomial int
+
+// Len returns the number of basis functions, n+1.
+func (p Polynomial) Len() int { return int(p) + 1 }
+
+// Eval places the powers of x into dst.
+func (p Polynomial) Eval(dst []float64, x float64) {
+	v := 1.0
+	for i := range dst[:p.Len()] {
+		dst[i] = v
+		v *= x
+	}
+}
+
+// Fourier is the trigonometric basis 1, cos(ωx), sin(ωx), …, cos(nωx),
+// sin(nωx), with ω = 2π/Period and n = Order.
+type Fourier struct {
+	Order  int
+	Period float64
+}
+
+// Len returns the number of basis functions, 2*Order+1.
+func (f Fourier) Len() int { return 2*f.Order + 1 }
+
+// Eval places the trigonometric functions of x into dst.
+func (f Fourier) Eval(dst []float64, x float64) {
+	dst[0] = 1
+	w := 2 * math.Pi / f.Period
+	for k := 1; k <= f.Order; k++ {
+		dst[2*k-1], dst[2*k] = math.Cos(float64(k)*w*x), math.Sin(float64(k)*w*x)
+	}
+}
+
+// Funcs is a basis of arbitrary functions.
+type Funcs []func(float64) float64
+
+// Len returns the number of basis functions.
+func (f Funcs) Len() int { return len(f) }
+
+// Eval places the values of the functions at x into dst.
+func (f Funcs) Eval(dst []float64, x float64) {
+	for i, fn := range f {
+		dst[i] = fn(x)
+	}
+}
+
+// Design returns the design matrix of the basis b evaluated at the
+// predictor values in x, with one row per value and one column per
+// basis function.
+func Design(b Basis, x []float64) *mat.Dense {
+	a := mat.NewDense(len(x), b.Len(), nil)
+	for i, v := range x {
+		b.Eval(a.RawRowView(i), v)
+	}
+	return a
+}
+
+// FitBasis returns the least squares fit of y on the basis b evaluated
+// at the predictor values in x, using the default singular value
+// tolerance.
+func FitBasis(b Basis, x, y []float64) (*Fit, error) {
+	return Lstsq(Design(b, x), mat.NewVecDense(len(y), y), DefaultTol)
+}
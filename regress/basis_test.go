@@ -0,0 +1,110 @@
+package regress
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestBasisEval(t *testing.T) {
+	const x = 0.3
+	for _, test := range []struct {
+		name string
+		b    Basis
+		want []float64
+	}{
+		{name: "constant", b: Polynomial(0), want: []float64{1}},
+		{name: "cubic", b: Polynomial(3), want: []float64{1, x, x * x, x * x * x}},
+		{
+			name: "fourier",
+			b:    Fourier{Order: 2, Period: 4},
+			want: []float64{
+				1,
+				math.Cos(math.Pi / 2 * x), math.Sin(math.Pi / 2 * x),
+				math.Cos(math.Pi * x), math.Sin(math.Pi * x),
+			},
+		},
+		{name: "funcs", b: Funcs{math.Exp, math.Sqrt}, want: []float64{math.Exp(x), math.Sqrt(x)}},
+	} {
+		if test.b.Len() != len(test.want) {
+			t.Errorf("unexpected length for %s: got:%d want:%d", test.name, test.b.Len(), len(test.want))
+			continue
+		}
+		got := make([]float64, test.b.Len())
+		test.b.Eval(got, x)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("unexpected values for %s: got:%v want:%v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDesign(t *testing.T) {
+	x := []float64{-1, 0, 2}
+	got := Design(Polynomial(2), x)
+	want := mat.NewDense(3, 3, []float64{
+		1, -1, 1,
+		1, 0, 0,
+		1, 2, 4,
+	})
+	if !mat.Equal(got, want) {
+		t.Errorf("unexpected design:\ngot: %v\nwant:%v", mat.Formatted(got), mat.Formatted(want))
+	}
+}
+
+func TestFitBasisExact(t *testing.T) {
+	x := make([]float64, 21)
+	floats.Span(x, -2, 2)
+	for _, test := range []struct {
+		name string
+		b    Basis
+		f    func(float64) float64
+		want []float64
+	}{
+		{
+			name: "polynomial",
+			b:    Polynomial(3),
+			f:    func(x float64) float64 { return 1 - 2*x + 0.5*x*x + 0.25*x*x*x },
+			want: []float64{1, -2, 0.5, 0.25},
+		},
+		{
+			// A higher degree than needed gives zero
+			// leading coefficients.
+			name: "overfit polynomial",
+			b:    Polynomial(5),
+			f:    func(x float64) float64 { return 3 + x*x },
+			want: []float64{3, 0, 1, 0, 0, 0},
+		},
+		{
+			name: "fourier",
+			b:    Fourier{Order: 2, Period: 4},
+			f:    func(x float64) float64 { return 0.5 + 2*math.Sin(math.Pi/2*x) - math.Cos(math.Pi*x) },
+			want: []float64{0.5, 0, 2, -1, 0},
+		},
+		{
+			name: "funcs",
+			b:    Funcs{math.Exp, math.Sin},
+			f:    func(x float64) float64 { return 2*math.Exp(x) - 3*math.Sin(x) },
+			want: []float64{2, -3},
+		},
+	} {
+		y := make([]float64, len(x))
+		for i, v := range x {
+			y[i] = test.f(v)
+		}
+		f, err := FitBasis(test.b, x, y)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.name, err)
+			continue
+		}
+		got := f.X.RawVector().Data
+		if !floats.EqualApprox(got, test.want, 1e-10) {
+			t.Errorf("unexpected coefficients for %s: got:%v want:%v", test.name, got, test.want)
+		}
+		if !scalar.EqualWithinAbs(f.R2, 1, 1e-12) {
+			t.Errorf("unexpected R2 for %s: got:%v want:1", test.name, f.R2)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package regress provides least squares regression and related model
+// fitting tools.
+package regress
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ErrFactorize is returned when a singular value decomposition fails.
+var ErrFactorize = errors.New("regress: failed to factorize matrix")
+
+// DefaultTol is the singular value tolerance used when a negative
+// tolerance is given. It selects the MATLAB default tolerance of
+// max(rows, cols) * σ_max * ε.
+const DefaultTol = -1
+
+// Pinv places the Moore-Penrose pseudo-inverse of a into dst and returns
+// the numerical rank of a. Singular values less than or equal to tol are
+// treated as zero. If tol is negative, the default MATLAB tolerance of
+// max(rows, cols) * σ_max * ε is used.
+func Pinv(dst *mat.Dense, a mat.Matrix, tol float64) (rank int, err error) {
+	var svd mat.SVD
+	ok := svd.Factorize(a, mat.SVDThin)
+	if !ok {
+		return 0, ErrFactorize
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	sigma := svd.Values(nil)
+	rank = numericalRank(sigma, a, tol)
+
+	rows, cols := a.Dims()
+	dst.Reset()
+	if rank == 0 {
+		dst.ReuseAs(cols, rows)
+		return 0, nil
+	}
+	inv := make([]float64, rank)
+	for i, s := range sigma[:rank] {
+		inv[i] = 1 / s
+	}
+	var vs mat.Dense
+	vs.Mul(v.Slice(0, cols, 0, rank), mat.NewDiagDense(rank, inv))
+	dst.Mul(&vs, u.Slice(0, rows, 0, rank).T())
+	return rank, nil
+}
+
+// numericalRank returns the number of singular values greater than tol,
+// or than the default tolerance if tol is negative.
+func numericalRank(sigma []float64, a mat.Matrix, tol float64) int {
+	if len(sigma) == 0 {
+		return 0
+	}
+	if tol < 0 {
+		rows, cols := a.Dims()
+		n := rows
+		if cols > n {
+			n = cols
+		}
+		tol = float64(n) * sigma[0] * eps
+	}
+	var rank int
+	for _, s := range sigma {
+		if s > tol {
+			rank++
+		}
+	}
+	return rank
+}
+
+// eps is the machine epsilon for float64.
+const eps = 0x1p-52
+
+// Fit is the result of a least squares fit of A x = b.
+type Fit struct {
+	// X holds the fitted coefficients.
+	X *mat.VecDense
+
+	// Rank is the numerical rank of A.
+	Rank int
+
+	// Fitted holds the fitted values, A x, and
+	// Residuals holds b - A x.
+	Fitted, Residuals *mat.VecDense
+
+	// RSS is the residual sum of squares.
+	RSS float64
+
+	// R2 is the coefficient of determination,
+	// 1 - RSS/TSS, where TSS is the total sum of
+	// squares about the mean of b, as reported by
+	// MATLAB's regress. If b is constant, TSS is
+	// zero and R2 is 1 if the fit is exact to within
+	// rounding error and 0 otherwise.
+	R2 float64
+
+	// DoF is the residual degrees of freedom,
+	// the number of observations minus Rank.
+	DoF int
+
+	// Variance is the estimated residual variance,
+	// RSS/DoF.
+	Variance float64
+
+	// StdErr, T and P hold the standard error,
+	// t-statistic and two-sided p-value of each
+	// coefficient. They are NaN when DoF is zero.
+	StdErr, T, P []float64
+
+	// cov is the unscaled coefficient covariance,
+	// (AᵀA)⁺.
+	cov *mat.SymDense
+}
+
+// Lstsq returns the minimum norm least squares solution of A x = b and
+// its diagnostics. Singular values of A less than or equal to tol are
+// treated as zero; if tol is negative, the default MATLAB tolerance of
+// max(rows, cols) * σ_max * ε is used.
+func Lstsq(a mat.Matrix, b mat.Vector, tol float64) (*Fit, error) {
+	rows, cols := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if rows == 0 || cols == 0 {
+		return nil, fmt.Errorf("regress: invalid design dimensions: %d×%d", rows, cols)
+	}
+
+	var svd mat.SVD
+	ok := svd.Factorize(a, mat.SVDThin)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	sigma := svd.Values(nil)
+	rank := numericalRank(sigma, a, tol)
+
+	f := &Fit{
+		X:    mat.NewVecDense(cols, nil),
+		Rank: rank,
+		DoF:  rows - rank,
+		cov:  mat.NewSymDense(cols, nil),
+	}
+	if rank != 0 {
+		// x = V Σ⁻¹ Uᵀ b over the retained singular values,
+		// and (AᵀA)⁺ = V Σ⁻² Vᵀ.
+		var utb mat.VecDense
+		utb.MulVec(u.Slice(0, rows, 0, rank).T(), b)
+		inv := make([]float64, rank)
+		for i, s := range sigma[:rank] {
+			inv[i] = 1 / s
+			utb.SetVec(i, utb.AtVec(i)/s)
+		}
+		vr := v.Slice(0, cols, 0, rank)
+		f.X.MulVec(vr, &utb)
+
+		var vs mat.Dense
+		vs.Mul(vr, mat.NewDiagDense(rank, inv))
+		f.cov.SymOuterK(1, &vs)
+	}
+
+	f.Fitted = mat.NewVecDense(rows, nil)
+	f.Fitted.MulVec(a, f.X)
+	f.Residuals = mat.NewVecDense(rows, nil)
+	f.Residuals.SubVec(b, f.Fitted)
+	f.RSS = mat.Dot(f.Residuals, f.Residuals)
+
+	y := make([]float64, rows)
+	mat.Col(y, 0, b)
+	if floats.Min(y) == floats.Max(y) {
+		// The mean of a constant response may not be exactly
+		// the constant, so handle this case explicitly.
+		if f.RSS <= float64(rows)*eps*floats.Dot(y, y) {
+			f.R2 = 1
+		}
+	} else {
+		mean := stat.Mean(y, nil)
+		var tss float64
+		for _, v := range y {
+			tss += (v - mean) * (v - mean)
+		}
+		f.R2 = 1 - f.RSS/tss
+	}
+
+	f.StdErr = make([]float64, cols)
+	f.T = make([]float64, cols)
+	f.P = make([]float64, cols)
+	if f.DoF <= 0 {
+		f.Variance = math.NaN()
+		for _, s := range [][]float64{f.StdErr, f.T, f.P} {
+			floats.AddConst(math.NaN(), s)
+		}
+		return f, nil
+	}
+	f.Variance = f.RSS / float64(f.DoF)
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(f.DoF)}
+	for j := range f.StdErr {
+		f.StdErr[j] = math.Sqrt(f.Variance * f.cov.At(j, j))
+		f.T[j] = f.X.AtVec(j) / f.StdErr[j]
+		f.P[j] = 2 * dist.Survival(math.Abs(f.T[j]))
+	}
+	return f, nil
+}
+
+// ConfInt returns the lower and upper bounds of the two-sided confidence
+// intervals of the coefficients at the given confidence level, for example
+// 0.95 for the 95% intervals reported by MATLAB's regress.
+func (f *Fit) ConfInt(level float64) (lower, upper []float64) {
+	if level <= 0 || 1 <= level {
+		panic("regress: confidence level out of range")
+	}
+	lower = make([]float64, f.X.Len())
+	upper = make([]float64, f.X.Len())
+	var t float64
+	if f.DoF > 0 {
+		t = distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(f.DoF)}.Quantile(1 - (1-level)/2)
+	} else {
+		t = math.NaN()
+	}
+	for j := range lower {
+		x := f.X.AtVec(j)
+		lower[j] = x - t*f.StdErr[j]
+		upper[j] = x + t*f.StdErr[j]
+	}
+	return lower, upper
+}
+
+// Cov returns the estimated covariance matrix of the coefficients.
+func (f *Fit) Cov() *mat.SymDense {
+	var c mat.SymDense
+	c.ScaleSym(f.Variance, f.cov)
+	return &c
+}
@@ -0,0 +1,279 @@
+package regress
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func randDense(r, c int, rnd *rand.Rand) *mat.Dense {
+	m := mat.NewDense(r, c, nil)
+	m.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, m)
+	return m
+}
+
+func TestPinv(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	full := randDense(8, 5, rnd)
+	deficient := mat.DenseCopyOf(full)
+	// Make the last column a combination of the first two.
+	for i := 0; i < 8; i++ {
+		deficient.Set(i, 4, deficient.At(i, 0)-2*deficient.At(i, 1))
+	}
+	for _, test := range []struct {
+		name string
+		a    mat.Matrix
+		rank int
+	}{
+		{name: "full", a: full, rank: 5},
+		{name: "wide", a: full.T(), rank: 5},
+		{name: "deficient", a: deficient, rank: 4},
+		{name: "zero", a: mat.NewDense(3, 2, nil), rank: 0},
+	} {
+		var p mat.Dense
+		rank, err := Pinv(&p, test.a, DefaultTol)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.name, err)
+			continue
+		}
+		if rank != test.rank {
+			t.Errorf("unexpected rank for %s: got:%d want:%d", test.name, rank, test.rank)
+		}
+
+		// Check the Moore-Penrose conditions.
+		var apa, pap, ap, pa mat.Dense
+		ap.Mul(test.a, &p)
+		pa.Mul(&p, test.a)
+		apa.Mul(&ap, test.a)
+		pap.Mul(&pa, &p)
+		if !mat.EqualApprox(&apa, test.a, 1e-12) {
+			t.Errorf("A A⁺ A != A for %s", test.name)
+		}
+		if !mat.EqualApprox(&pap, &p, 1e-12) {
+			t.Errorf("A⁺ A A⁺ != A⁺ for %s", test.name)
+		}
+		if !mat.EqualApprox(&ap, ap.T(), 1e-12) {
+			t.Errorf("A A⁺ not symmetric for %s", test.name)
+		}
+		if !mat.EqualApprox(&pa, pa.T(), 1e-12) {
+			t.Errorf("A⁺ A not symmetric for %s", test.name)
+		}
+	}
+
+	// A large tolerance discards all but the largest singular value.
+	var svd mat.SVD
+	if !svd.Factorize(full, mat.SVDNone) {
+		t.Fatal("failed to factorize")
+	}
+	var p mat.Dense
+	rank, err := Pinv(&p, full, svd.Values(nil)[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rank != 1 {
+		t.Errorf("unexpected rank with large tolerance: got:%d want:1", rank)
+	}
+}
+
+func TestLstsqExact(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a := randDense(10, 3, rnd)
+	want := mat.NewVecDense(3, []float64{1, -2, 0.5})
+	var b mat.VecDense
+	b.MulVec(a, want)
+
+	f, err := Lstsq(a, &b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(f.X, want, 1e-12) {
+		t.Errorf("unexpected coefficients: got:%v want:%v", f.X.RawVector().Data, want.RawVector().Data)
+	}
+	if f.Rank != 3 || f.DoF != 7 {
+		t.Errorf("unexpected rank and degrees of freedom: got:%d,%d want:3,7", f.Rank, f.DoF)
+	}
+	if f.RSS > 1e-24 || !scalar.EqualWithinAbs(f.R2, 1, 1e-12) {
+		t.Errorf("unexpected fit statistics for exact data: RSS=%v R2=%v", f.RSS, f.R2)
+	}
+}
+
+func TestLstsqMinimumNorm(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a := randDense(6, 3, rnd)
+	for i := 0; i < 6; i++ {
+		a.Set(i, 2, a.At(i, 0))
+	}
+	b := mat.NewVecDense(6, nil)
+	for i := 0; i < 6; i++ {
+		b.SetVec(i, rnd.NormFloat64())
+	}
+	f, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Rank != 2 {
+		t.Errorf("unexpected rank: got:%d want:2", f.Rank)
+	}
+	var p mat.Dense
+	_, err = Pinv(&p, a, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var want mat.VecDense
+	want.MulVec(&p, b)
+	if !mat.EqualApprox(f.X, &want, 1e-12) {
+		t.Errorf("unexpected minimum norm solution: got:%v want:%v", f.X.RawVector().Data, want.RawVector().Data)
+	}
+	// The weight is split equally between the duplicated columns.
+	if !scalar.EqualWithinAbs(f.X.AtVec(0), f.X.AtVec(2), 1e-12) {
+		t.Errorf("unexpected split between duplicated columns: %v", f.X.RawVector().Data)
+	}
+}
+
+func TestLstsqDiagnostics(t *testing.T) {
+	// Simple linear regression has closed form diagnostics.
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	y := []float64{1.2, 1.9, 3.2, 3.8, 5.1, 6.3, 6.8, 8.1}
+	n := float64(len(x))
+	a := mat.NewDense(len(x), 2, nil)
+	for i, v := range x {
+		a.Set(i, 0, 1)
+		a.Set(i, 1, v)
+	}
+	f, err := Lstsq(a, mat.NewVecDense(len(y), y), DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var mx, my float64
+	for i := range x {
+		mx += x[i] / n
+		my += y[i] / n
+	}
+	var sxx, sxy, syy float64
+	for i := range x {
+		sxx += (x[i] - mx) * (x[i] - mx)
+		sxy += (x[i] - mx) * (y[i] - my)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	slope := sxy / sxx
+	intercept := my - slope*mx
+	rss := syy - slope*sxy
+	s2 := rss / (n - 2)
+	seSlope := math.Sqrt(s2 / sxx)
+	seIntercept := math.Sqrt(s2 * (1/n + mx*mx/sxx))
+
+	const tol = 1e-12
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{name: "intercept", got: f.X.AtVec(0), want: intercept},
+		{name: "slope", got: f.X.AtVec(1), want: slope},
+		{name: "RSS", got: f.RSS, want: rss},
+		{name: "R2", got: f.R2, want: 1 - rss/syy},
+		{name: "variance", got: f.Variance, want: s2},
+		{name: "intercept standard error", got: f.StdErr[0], want: seIntercept},
+		{name: "slope standard error", got: f.StdErr[1], want: seSlope},
+		{name: "slope t", got: f.T[1], want: slope / seSlope},
+		{name: "slope p", got: f.P[1], want: 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: n - 2}.Survival(slope/seSlope)},
+	} {
+		if !scalar.EqualWithinAbsOrRel(test.got, test.want, tol, tol) {
+			t.Errorf("unexpected %s: got:%v want:%v", test.name, test.got, test.want)
+		}
+	}
+
+	lower, upper := f.ConfInt(0.95)
+	q := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: n - 2}.Quantile(0.975)
+	for j, se := range []float64{seIntercept, seSlope} {
+		x := f.X.AtVec(j)
+		if !scalar.EqualWithinAbsOrRel(lower[j], x-q*se, tol, tol) || !scalar.EqualWithinAbsOrRel(upper[j], x+q*se, tol, tol) {
+			t.Errorf("unexpected confidence interval %d: got:[%v, %v] want:[%v, %v]", j, lower[j], upper[j], x-q*se, x+q*se)
+		}
+	}
+
+	var ata, want mat.Dense
+	ata.Mul(a.T(), a)
+	err = want.Inverse(&ata)
+	if err != nil {
+		t.Fatalf("unexpected error inverting AᵀA: %v", err)
+	}
+	want.Scale(s2, &want)
+	if cov := f.Cov(); !mat.EqualApprox(cov, &want, tol) {
+		t.Errorf("unexpected covariance:\ngot: %v\nwant:%v", mat.Formatted(cov), mat.Formatted(&want))
+	}
+	if !scalar.EqualWithinAbsOrRel(f.Cov().At(1, 1), seSlope*seSlope, tol, tol) {
+		t.Errorf("covariance does not match slope standard error")
+	}
+}
+
+func TestLstsqConstantResponse(t *testing.T) {
+	a := mat.NewDense(4, 2, []float64{
+		1, 1,
+		1, 2,
+		1, 3,
+		1, 4,
+	})
+	for _, test := range []struct {
+		name string
+		a    mat.Matrix
+		want float64
+	}{
+		// An intercept fits a constant response exactly.
+		{name: "intercept", a: a, want: 1},
+		// A line through the origin cannot.
+		{name: "no intercept", a: a.Slice(0, 4, 1, 2), want: 0},
+	} {
+		b := mat.NewVecDense(4, []float64{0.1, 0.1, 0.1, 0.1})
+		f, err := Lstsq(test.a, b, DefaultTol)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.name, err)
+			continue
+		}
+		if f.R2 != test.want {
+			t.Errorf("unexpected R2 for %s: got:%v want:%v", test.name, f.R2, test.want)
+		}
+	}
+
+	_, err := Lstsq(a, mat.NewVecDense(3, nil), DefaultTol)
+	if err == nil {
+		t.Error("expected error for mismatched response length")
+	}
+}
+
+func TestLstsqSaturated(t *testing.T) {
+	a := mat.NewDense(2, 2, []float64{1, 0, 1, 1})
+	f, err := Lstsq(a, mat.NewVecDense(2, []float64{1, 3}), DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.DoF != 0 || !math.IsNaN(f.Variance) {
+		t.Errorf("unexpected saturated fit: DoF=%d variance=%v", f.DoF, f.Variance)
+	}
+	lower, upper := f.ConfInt(0.95)
+	for j := range lower {
+		if !math.IsNaN(f.StdErr[j]) || !math.IsNaN(lower[j]) || !math.IsNaN(upper[j]) {
+			t.Errorf("expected NaN diagnostics for coefficient %d: se=%v ci=[%v, %v]", j, f.StdErr[j], lower[j], upper[j])
+		}
+	}
+}
+
+func TestLstsqErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a    mat.Matrix
+		b    mat.Vector
+	}{
+		{name: "response length", a: mat.NewDense(3, 2, nil), b: mat.NewVecDense(2, nil)},
+		{name: "no observations", a: &mat.Dense{}, b: &mat.VecDense{}},
+	} {
+		_, err := Lstsq(test.a, test.b, DefaultTol)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
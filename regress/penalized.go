@@ -0,0 +1,201 @@
+package regress
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrNotConverged is returned when an iterative solver does not reach its
+// tolerance within the allowed number of iterations.
+var ErrNotConverged = errors.New("regress: solver did not converge")
+
+// Path holds the coefficients of a penalized regression over a sequence
+// of regularization parameters.
+//
+// The penalized solvers do not fit an intercept. The columns of the
+// design matrix should be standardized and the response centered before
+// fitting, for example with the preprocess package, so that the penalty
+// treats each coefficient equally.
+type Path struct {
+	// Lambda holds the regularization parameters.
+	Lambda []float64
+
+	// Coef holds the coefficients for Lambda[i]
+	// in its ith column.
+	Coef *mat.Dense
+}
+
+// Ridge returns the ridge regression path minimizing ‖b - A x‖² + λ‖x‖²
+// for each λ in lambdas. All solutions are computed from a single singular
+// value decomposition of A, x = V diag(σᵢ/(σᵢ²+λ)) Uᵀ b.
+func Ridge(a mat.Matrix, b mat.Vector, lambdas []float64) (*Path, error) {
+	rows, cols := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	var svd mat.SVD
+	ok := svd.Factorize(a, mat.SVDThin)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	sigma := svd.Values(nil)
+	var utb mat.VecDense
+	utb.MulVec(u.T(), b)
+
+	p := &Path{
+		Lambda: append([]float64(nil), lambdas...),
+		Coef:   mat.NewDense(cols, len(lambdas), nil),
+	}
+	d := mat.NewVecDense(len(sigma), nil)
+	for k, lambda := range lambdas {
+		if lambda < 0 {
+			return nil, fmt.Errorf("regress: negative regularization parameter: %v", lambda)
+		}
+		for i, s := range sigma {
+			if s == 0 {
+				d.SetVec(i, 0)
+				continue
+			}
+			d.SetVec(i, s/(s*s+lambda)*utb.AtVec(i))
+		}
+		p.Coef.ColView(k).(*mat.VecDense).MulVec(&v, d)
+	}
+	return p, nil
+}
+
+// Lasso returns the LASSO regression path for each λ in lambdas. It is
+// equivalent to ElasticNet with alpha = 1.
+func Lasso(a mat.Matrix, b mat.Vector, lambdas []float64) (*Path, error) {
+	return ElasticNet(a, b, 1, lambdas)
+}
+
+// ElasticNet returns the elastic net regression path minimizing
+// ‖b - A x‖²/(2n) + λ(α‖x‖₁ + (1-α)‖x‖²/2) for n observations and each
+// λ in lambdas, following the convention of glmnet. The mixing parameter
+// alpha must be in [0, 1]; alpha = 1 is the LASSO and alpha = 0 is ridge
+// regression with a penalty of nλ in the parameterization used by Ridge.
+//
+// The path is computed by cyclic coordinate descent, warm starting each
+// solution from the previous one, so lambdas should be in decreasing
+// order for efficiency. If a solution does not converge, the path computed
+// so far is returned with ErrNotConverged.
+func ElasticNet(a mat.Matrix, b mat.Vector, alpha float64, lambdas []float64) (*Path, error) {
+	rows, cols := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if alpha < 0 || 1 < alpha {
+		return nil, fmt.Errorf("regress: mixing parameter out of range: %v", alpha)
+	}
+	const (
+		tol     = 1e-7
+		maxIter = 100000
+	)
+
+	ad := mat.DenseCopyOf(a)
+	n := float64(rows)
+	col := make([][]float64, cols)
+	z := make([]float64, cols)
+	for j := range col {
+		col[j] = mat.Col(nil, j, ad)
+		z[j] = floats.Dot(col[j], col[j]) / n
+	}
+	r := mat.Col(nil, 0, b)
+	x := make([]float64, cols)
+
+	p := &Path{
+		Lambda: append([]float64(nil), lambdas...),
+		Coef:   mat.NewDense(cols, len(lambdas), nil),
+	}
+	for k, lambda := range lambdas {
+		if lambda < 0 {
+			return nil, fmt.Errorf("regress: negative regularization parameter: %v", lambda)
+		}
+		converged := false
+		for iter := 0; iter < maxIter; iter++ {
+			var maxDelta float64
+			for j, c := range col {
+				if z[j] == 0 {
+					continue
+				}
+				rho := floats.Dot(c, r)/n + z[j]*x[j]
+				xj := shrink(rho, lambda*alpha) / (z[j] + lambda*(1-alpha))
+				if delta := xj - x[j]; delta != 0 {
+					floats.AddScaled(r, -delta, c)
+					maxDelta = math.Max(maxDelta, z[j]*delta*delta)
+					x[j] = xj
+				}
+			}
+			if maxDelta < tol {
+				converged = true
+				break
+			}
+		}
+		p.Coef.SetCol(k, x)
+		if !converged {
+			p.Lambda = p.Lambda[:k+1]
+			p.Coef = p.Coef.Slice(0, cols, 0, k+1).(*mat.Dense)
+			return p, ErrNotConverged
+		}
+	}
+	return p, nil
+}
+
+// LambdaMax returns the smallest regularization parameter for which all
+// the elastic net coefficients of b on A are zero, max|Aᵀb|/(nα) for n
+// observations. It is infinite when alpha is zero, since ridge regression
+// never sets coefficients to zero.
+func LambdaMax(a mat.Matrix, b mat.Vector, alpha float64) float64 {
+	if alpha == 0 {
+		return math.Inf(1)
+	}
+	rows, cols := a.Dims()
+	n := float64(rows)
+	// Compute the correlations in the same way as
+	// ElasticNet so that the first solution of a
+	// path starting at the returned value is zero.
+	r := mat.Col(nil, 0, b)
+	col := make([]float64, rows)
+	var max float64
+	for j := 0; j < cols; j++ {
+		mat.Col(col, j, a)
+		max = math.Max(max, math.Abs(floats.Dot(col, r)/n))
+	}
+	lambda := max / alpha
+	if lambda*alpha < max {
+		lambda = math.Nextafter(lambda, math.Inf(1))
+	}
+	return lambda
+}
+
+// LambdaGrid returns n regularization parameters spaced logarithmically
+// in decreasing order from max to max*ratio. The end points are exact.
+// If n is less than two, LambdaGrid returns nil.
+func LambdaGrid(max, ratio float64, n int) []float64 {
+	if n < 2 {
+		return nil
+	}
+	grid := floats.LogSpan(make([]float64, n), max, max*ratio)
+	grid[0] = max
+	grid[n-1] = max * ratio
+	return grid
+}
+
+// shrink returns the soft thresholding of v at tau.
+func shrink(v, tau float64) float64 {
+	switch {
+	case v > tau:
+		return v - tau
+	case v < -tau:
+		return v + tau
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,260 @@
+package regress
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+)
+
+// penalizedData returns a centered response generated from a sparse
+// linear model of a random design.
+func penalizedData(rnd *rand.Rand) (*mat.Dense, *mat.VecDense) {
+	const (
+		rows = 60
+		cols = 6
+	)
+	a := randDense(rows, cols, rnd)
+	x := mat.NewVecDense(cols, []float64{3, 0, -2, 0, 0, 1})
+	var b mat.VecDense
+	b.MulVec(a, x)
+	for i := 0; i < rows; i++ {
+		b.SetVec(i, b.AtVec(i)+0.5*rnd.NormFloat64())
+	}
+	return a, &b
+}
+
+func TestRidge(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := penalizedData(rnd)
+	_, cols := a.Dims()
+	lambdas := []float64{10, 1, 0}
+	p, err := Ridge(a, b, lambdas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(p.Coef.ColView(2), f.X, 1e-12) {
+		t.Errorf("ridge with λ=0 does not match least squares: got:%v want:%v",
+			mat.Col(nil, 2, p.Coef), f.X.RawVector().Data)
+	}
+
+	// Compare with the regularized normal equations.
+	for k, lambda := range lambdas {
+		var ata mat.SymDense
+		ata.SymOuterK(1, a.T())
+		for i := 0; i < cols; i++ {
+			ata.SetSym(i, i, ata.At(i, i)+lambda)
+		}
+		var atb, want mat.VecDense
+		atb.MulVec(a.T(), b)
+		err := want.SolveVec(&ata, &atb)
+		if err != nil {
+			t.Fatalf("unexpected error solving normal equations: %v", err)
+		}
+		if !mat.EqualApprox(p.Coef.ColView(k), &want, 1e-12) {
+			t.Errorf("unexpected ridge coefficients for λ=%v: got:%v want:%v",
+				lambda, mat.Col(nil, k, p.Coef), want.RawVector().Data)
+		}
+	}
+}
+
+func TestLassoLambdaMax(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := penalizedData(rnd)
+	_, cols := a.Dims()
+	for _, alpha := range []float64{1, 0.5, 0.3} {
+		max := LambdaMax(a, b, alpha)
+		p, err := ElasticNet(a, b, alpha, []float64{max, 0.99 * max})
+		if err != nil {
+			t.Fatalf("unexpected error for alpha=%v: %v", alpha, err)
+		}
+		got := mat.Col(nil, 0, p.Coef)
+		if !floats.Equal(got, make([]float64, cols)) {
+			t.Errorf("unexpected non-zero coefficients at λmax for alpha=%v: %v", alpha, got)
+		}
+		got = mat.Col(nil, 1, p.Coef)
+		var nonzero int
+		for _, v := range got {
+			if v != 0 {
+				nonzero++
+			}
+		}
+		if nonzero != 1 {
+			t.Errorf("unexpected number of coefficients below λmax for alpha=%v: got:%d want:1", alpha, nonzero)
+		}
+	}
+	if max := LambdaMax(a, b, 0); !math.IsInf(max, 1) {
+		t.Errorf("unexpected λmax for alpha=0: got:%v want:+Inf", max)
+	}
+	// Uncorrelated columns give a zero λmax unless alpha is zero.
+	zero := mat.NewVecDense(b.Len(), nil)
+	if max := LambdaMax(a, zero, 1); max != 0 {
+		t.Errorf("unexpected λmax for zero correlations: got:%v want:0", max)
+	}
+	if max := LambdaMax(a, zero, 0); !math.IsInf(max, 1) {
+		t.Errorf("unexpected λmax for zero correlations and alpha=0: got:%v want:+Inf", max)
+	}
+}
+
+func TestElasticNetOrthogonal(t *testing.T) {
+	// With an orthogonal design, AᵀA = nI, the elastic net has the closed
+	// form solution xⱼ = S(aⱼᵀb/n, λα)/(1+λ(1-α)).
+	const (
+		rows = 40
+		cols = 5
+	)
+	rnd := rand.New(rand.NewSource(1))
+	var qr mat.QR
+	qr.Factorize(randDense(rows, cols, rnd))
+	var q mat.Dense
+	qr.QTo(&q)
+	a := mat.DenseCopyOf(q.Slice(0, rows, 0, cols))
+	a.Scale(math.Sqrt(rows), a)
+	b := mat.NewVecDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		b.SetVec(i, rnd.NormFloat64())
+	}
+	var atb mat.VecDense
+	atb.MulVec(a.T(), b)
+	atb.ScaleVec(1.0/rows, &atb)
+
+	lambdas := LambdaGrid(LambdaMax(a, b, 1), 0.01, 10)
+	for _, alpha := range []float64{1, 0.7, 0.2} {
+		p, err := ElasticNet(a, b, alpha, lambdas)
+		if err != nil {
+			t.Fatalf("unexpected error for alpha=%v: %v", alpha, err)
+		}
+		for k, lambda := range lambdas {
+			want := make([]float64, cols)
+			for j := range want {
+				want[j] = shrink(atb.AtVec(j), lambda*alpha) / (1 + lambda*(1-alpha))
+			}
+			got := mat.Col(nil, k, p.Coef)
+			if !floats.EqualApprox(got, want, 1e-6) {
+				t.Errorf("unexpected coefficients for alpha=%v λ=%v: got:%v want:%v", alpha, lambda, got, want)
+			}
+		}
+	}
+}
+
+func TestLassoOptimality(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := penalizedData(rnd)
+	rows, cols := a.Dims()
+	n := float64(rows)
+	lambdas := LambdaGrid(LambdaMax(a, b, 1), 1e-3, 20)
+	p, err := Lasso(a, b, lambdas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Check the subgradient conditions of the LASSO objective:
+	// |aⱼᵀr/n| ≤ λ, with equality and matching sign for xⱼ ≠ 0,
+	// to within the accuracy of the coordinate descent.
+	const tol = 2e-4
+	for k, lambda := range lambdas {
+		var r mat.VecDense
+		r.MulVec(a, p.Coef.ColView(k))
+		r.SubVec(b, &r)
+		for j := 0; j < cols; j++ {
+			g := mat.Dot(a.ColView(j), &r) / n
+			x := p.Coef.At(j, k)
+			switch {
+			case x == 0:
+				if math.Abs(g) > lambda+tol {
+					t.Errorf("subgradient condition violated for zero coefficient %d at λ=%v: |g|=%v", j, lambda, math.Abs(g))
+				}
+			default:
+				if !scalar.EqualWithinAbs(g, math.Copysign(lambda, x), tol) {
+					t.Errorf("subgradient condition violated for coefficient %d at λ=%v: got:%v want:%v", j, lambda, g, math.Copysign(lambda, x))
+				}
+			}
+		}
+	}
+
+	// The smallest penalty approaches least squares.
+	f, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(p.Coef.ColView(len(lambdas)-1), f.X, 0.01) {
+		t.Errorf("small penalty LASSO does not approach least squares: got:%v want:%v",
+			mat.Col(nil, len(lambdas)-1, p.Coef), f.X.RawVector().Data)
+	}
+}
+
+func TestElasticNetRidge(t *testing.T) {
+	// With alpha = 0 the elastic net is ridge regression with
+	// a penalty of nλ.
+	rnd := rand.New(rand.NewSource(1))
+	a, b := penalizedData(rnd)
+	rows, _ := a.Dims()
+	lambdas := []float64{1, 0.1}
+	enet, err := ElasticNet(a, b, 0, lambdas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scaled := make([]float64, len(lambdas))
+	floats.ScaleTo(scaled, float64(rows), lambdas)
+	ridge, err := Ridge(a, b, scaled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(enet.Coef, ridge.Coef, 1e-4) {
+		t.Errorf("unexpected elastic net coefficients for alpha=0:\ngot: %v\nwant:%v",
+			mat.Formatted(enet.Coef), mat.Formatted(ridge.Coef))
+	}
+}
+
+func TestPenalizedErrors(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := penalizedData(rnd)
+	short := mat.NewVecDense(3, nil)
+	for _, test := range []struct {
+		name string
+		fn   func() (*Path, error)
+	}{
+		{name: "ridge short response", fn: func() (*Path, error) { return Ridge(a, short, []float64{1}) }},
+		{name: "ridge negative λ", fn: func() (*Path, error) { return Ridge(a, b, []float64{1, -1}) }},
+		{name: "elastic net short response", fn: func() (*Path, error) { return ElasticNet(a, short, 0.5, []float64{1}) }},
+		{name: "elastic net negative λ", fn: func() (*Path, error) { return ElasticNet(a, b, 0.5, []float64{-1}) }},
+		{name: "elastic net alpha too small", fn: func() (*Path, error) { return ElasticNet(a, b, -0.1, []float64{1}) }},
+		{name: "elastic net alpha too large", fn: func() (*Path, error) { return ElasticNet(a, b, 1.1, []float64{1}) }},
+	} {
+		_, err := test.fn()
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestLambdaGrid(t *testing.T) {
+	got := LambdaGrid(100, 1e-3, 4)
+	want := []float64{100, 10, 1, 0.1}
+	if !floats.EqualApprox(got, want, 1e-12) {
+		t.Errorf("unexpected grid: got:%v want:%v", got, want)
+	}
+	// The end points are exact.
+	const max, ratio = 0.7312, 0.003
+	got = LambdaGrid(max, ratio, 7)
+	if got[0] != max || got[6] != max*ratio {
+		t.Errorf("unexpected grid end points: got:%v,%v want:%v,%v", got[0], got[6], max, max*ratio)
+	}
+	got = LambdaGrid(max, ratio, 2)
+	if len(got) != 2 || got[0] != max || got[1] != max*ratio {
+		t.Errorf("unexpected two point grid: got:%v want:[%v %v]", got, max, max*ratio)
+	}
+	for _, n := range []int{1, 0, -1} {
+		if got := LambdaGrid(max, ratio, n); got != nil {
+			t.Errorf("unexpected grid for n=%d: got:%v want:nil", n, got)
+		}
+	}
+}
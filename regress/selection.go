@@ -0,0 +1,90 @@
+package regress
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// AIC returns the Akaike information criterion of the fit assuming
+// Gaussian errors, n ln(RSS/n) + 2k, for n observations and k = Rank
+// fitted parameters. Constant terms common to all models of the same
+// data are omitted, so only differences in AIC are meaningful.
+//
+// The criterion is -Inf when RSS is zero, as it is for a model that
+// interpolates the data, so such a model is always preferred. The
+// criteria are only meaningful for models with residual degrees of
+// freedom; exclude exact fits from comparisons.
+func (f *Fit) AIC() float64 {
+	n := float64(f.Residuals.Len())
+	return n*math.Log(f.RSS/n) + 2*float64(f.Rank)
+}
+
+// BIC returns the Bayesian information criterion of the fit assuming
+// Gaussian errors, n ln(RSS/n) + k ln(n), for n observations and k = Rank
+// fitted parameters. As for AIC, only differences in BIC are meaningful,
+// and BIC is -Inf when RSS is zero.
+func (f *Fit) BIC() float64 {
+	n := float64(f.Residuals.Len())
+	return n*math.Log(f.RSS/n) + float64(f.Rank)*math.Log(n)
+}
+
+// Predict places the predictions of the fitted model for the design
+// matrix a into dst.
+func (f *Fit) Predict(dst *mat.VecDense, a mat.Matrix) {
+	dst.Reset()
+	dst.MulVec(a, f.X)
+}
+
+// CrossValidate returns the mean squared prediction error of least
+// squares fits of b on the design matrix a estimated by k-fold cross
+// validation. The rows are randomly permuted using rnd before being split
+// into k folds of near equal size. If rnd is nil, a source with a fixed
+// seed is used so that the result is reproducible.
+func CrossValidate(a mat.Matrix, b mat.Vector, k int, rnd *rand.Rand) (float64, error) {
+	rows, cols := a.Dims()
+	if b.Len() != rows {
+		return 0, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if k < 2 || k > rows {
+		return 0, fmt.Errorf("regress: invalid number of folds: %d", k)
+	}
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(1))
+	}
+	idx := rnd.Perm(rows)
+
+	var sse float64
+	for fold := 0; fold < k; fold++ {
+		lo, hi := fold*rows/k, (fold+1)*rows/k
+		n := rows - (hi - lo)
+		train := mat.NewDense(n, cols, nil)
+		target := mat.NewVecDense(n, nil)
+		var i int
+		for j, r := range idx {
+			if lo <= j && j < hi {
+				continue
+			}
+			for c := 0; c < cols; c++ {
+				train.Set(i, c, a.At(r, c))
+			}
+			target.SetVec(i, b.AtVec(r))
+			i++
+		}
+		f, err := Lstsq(train, target, DefaultTol)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range idx[lo:hi] {
+			var pred float64
+			for c := 0; c < cols; c++ {
+				pred += a.At(r, c) * f.X.AtVec(c)
+			}
+			d := b.AtVec(r) - pred
+			sse += d * d
+		}
+	}
+	return sse / float64(rows), nil
+}
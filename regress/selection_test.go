@@ -0,0 +1,205 @@
+package regress
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+)
+
+// noisyCubic returns n noisy observations of a cubic polynomial.
+func noisyCubic(n int, rnd *rand.Rand) (x, y []float64) {
+	x = make([]float64, n)
+	y = make([]float64, n)
+	floats.Span(x, -1, 1)
+	for i, v := range x {
+		y[i] = 1 - 2*v + 3*v*v*v + 0.1*rnd.NormFloat64()
+	}
+	return x, y
+}
+
+func TestInformationCriteria(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x, y := noisyCubic(50, rnd)
+	n := float64(len(x))
+
+	bestAIC, bestBIC := -1, -1
+	minAIC, minBIC := math.Inf(1), math.Inf(1)
+	for deg := 0; deg <= 8; deg++ {
+		f, err := FitBasis(Polynomial(deg), x, y)
+		if err != nil {
+			t.Fatalf("unexpected error for degree %d: %v", deg, err)
+		}
+		k := float64(deg + 1)
+		if want := n*math.Log(f.RSS/n) + 2*k; !scalar.EqualWithinAbsOrRel(f.AIC(), want, 1e-12, 1e-12) {
+			t.Errorf("unexpected AIC for degree %d: got:%v want:%v", deg, f.AIC(), want)
+		}
+		if want := n*math.Log(f.RSS/n) + k*math.Log(n); !scalar.EqualWithinAbsOrRel(f.BIC(), want, 1e-12, 1e-12) {
+			t.Errorf("unexpected BIC for degree %d: got:%v want:%v", deg, f.BIC(), want)
+		}
+		if f.AIC() < minAIC {
+			minAIC, bestAIC = f.AIC(), deg
+		}
+		if f.BIC() < minBIC {
+			minBIC, bestBIC = f.BIC(), deg
+		}
+	}
+	// AIC may overfit, but never selects too small a model here.
+	if bestAIC < 3 {
+		t.Errorf("unexpected degree selected by AIC: got:%d want:≥3", bestAIC)
+	}
+	if bestBIC != 3 {
+		t.Errorf("unexpected degree selected by BIC: got:%d want:3", bestBIC)
+	}
+}
+
+func TestInformationCriteriaExact(t *testing.T) {
+	// An interpolating fit has zero RSS and so is preferred
+	// to any fit with residuals.
+	exact := &Fit{Residuals: mat.NewVecDense(4, nil), Rank: 4}
+	if got := exact.AIC(); !math.IsInf(got, -1) {
+		t.Errorf("unexpected AIC for exact fit: got:%v want:-Inf", got)
+	}
+	if got := exact.BIC(); !math.IsInf(got, -1) {
+		t.Errorf("unexpected BIC for exact fit: got:%v want:-Inf", got)
+	}
+
+	// A polynomial through every point interpolates the data, and
+	// the criteria are dominated by the vanishing RSS.
+	rnd := rand.New(rand.NewSource(1))
+	x, y := noisyCubic(6, rnd)
+	interp, err := FitBasis(Polynomial(5), x, y)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cubic, err := FitBasis(Polynomial(3), x, y)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interp.DoF != 0 {
+		t.Errorf("unexpected residual degrees of freedom: got:%d want:0", interp.DoF)
+	}
+	if interp.AIC() >= cubic.AIC() || interp.BIC() >= cubic.BIC() {
+		t.Errorf("interpolating fit not preferred: AIC %v vs %v, BIC %v vs %v",
+			interp.AIC(), cubic.AIC(), interp.BIC(), cubic.BIC())
+	}
+}
+
+func TestPredict(t *testing.T) {
+	x := []float64{0, 1, 2, 3}
+	y := []float64{1, 3, 5, 7}
+	f, err := FitBasis(Polynomial(1), x, y)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got mat.VecDense
+	f.Predict(&got, Design(Polynomial(1), []float64{-1, 10}))
+	if want := []float64{-1, 21}; !floats.EqualApprox(got.RawVector().Data, want, 1e-12) {
+		t.Errorf("unexpected predictions: got:%v want:%v", got.RawVector().Data, want)
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x, y := noisyCubic(30, rnd)
+	a := Design(Polynomial(3), x)
+	b := mat.NewVecDense(len(y), y)
+
+	// Leave-one-out cross validation does not depend on the
+	// permutation and has the closed form mean((eᵢ/(1-hᵢᵢ))²).
+	f, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var p, h mat.Dense
+	_, err = Pinv(&p, a, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h.Mul(a, &p)
+	var want float64
+	for i := range y {
+		e := f.Residuals.AtVec(i) / (1 - h.At(i, i))
+		want += e * e
+	}
+	want /= float64(len(y))
+	got, err := CrossValidate(a, b, len(y), rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !scalar.EqualWithinAbsOrRel(got, want, 1e-10, 1e-10) {
+		t.Errorf("unexpected leave-one-out error: got:%v want:%v", got, want)
+	}
+
+	// The same seed gives the same folds.
+	first, err := CrossValidate(a, b, 5, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := CrossValidate(a, b, 5, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("cross validation not deterministic for fixed seed: %v != %v", first, second)
+	}
+	// A nil source uses a fixed seed that does not depend on the
+	// demo seed environment variable.
+	old, ok := os.LookupEnv("DATABOOK_SEED")
+	defer func() {
+		if ok {
+			os.Setenv("DATABOOK_SEED", old)
+		} else {
+			os.Unsetenv("DATABOOK_SEED")
+		}
+	}()
+	os.Setenv("DATABOOK_SEED", "42")
+	first, err = CrossValidate(a, b, 5, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.Setenv("DATABOOK_SEED", "invalid")
+	second, err = CrossValidate(a, b, 5, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("cross validation with nil source depends on the environment: %v != %v", first, second)
+	}
+	// The error is of the order of the noise variance.
+	if first < 0.005 || first > 0.02 {
+		t.Errorf("unexpected 5-fold error: got:%v want:≈0.01", first)
+	}
+
+	// Exact data has no prediction error.
+	exact := mat.NewVecDense(len(x), nil)
+	for i, v := range x {
+		exact.SetVec(i, 1-2*v+3*v*v*v)
+	}
+	got, err = CrossValidate(a, exact, 5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got > 1e-20 {
+		t.Errorf("unexpected error for exact data: got:%v want:0", got)
+	}
+
+	for _, test := range []struct {
+		name string
+		b    mat.Vector
+		k    int
+	}{
+		{name: "short response", b: mat.NewVecDense(3, nil), k: 5},
+		{name: "one fold", b: b, k: 1},
+		{name: "too many folds", b: b, k: len(y) + 1},
+	} {
+		_, err := CrossValidate(a, test.b, test.k, rand.New(rand.NewSource(1)))
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package regress
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ErrNonGeneric is returned when a total least squares problem has no
+// solution because the smallest right singular vector of the augmented
+// matrix has a zero response component.
+var ErrNonGeneric = errors.New("regress: total least squares problem is not generic")
+
+// TLS returns the total least squares solution of A x ≈ b, which allows
+// for errors in both A and b of equal variance. The solution is obtained
+// from the right singular vector, v, of the augmented matrix [A b] that
+// corresponds to its smallest singular value, x = -v[:n]/v[n] for n
+// columns of A.
+func TLS(a mat.Matrix, b mat.Vector) (*mat.VecDense, error) {
+	rows, cols := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if rows <= cols {
+		return nil, fmt.Errorf("regress: too few observations for total least squares: %d", rows)
+	}
+	var ab mat.Dense
+	ab.Augment(a, b)
+	var svd mat.SVD
+	ok := svd.Factorize(&ab, mat.SVDThinV)
+	if !ok {
+		return nil, ErrFactorize
+	}
+	var v mat.Dense
+	svd.VTo(&v)
+	vbb := v.At(cols, cols)
+	if vbb == 0 {
+		return nil, ErrNonGeneric
+	}
+	x := mat.NewVecDense(cols, nil)
+	for i := 0; i < cols; i++ {
+		x.SetVec(i, -v.At(i, cols)/vbb)
+	}
+	return x, nil
+}
+
+// WLS returns the weighted least squares fit of A x = b minimizing
+// Σ wᵢ(bᵢ - aᵢx)². Weights are typically the inverse variances of the
+// observation errors and must not be negative.
+//
+// The fit is computed by scaling each row of the problem by √wᵢ and all
+// the diagnostics of the returned Fit, including Fitted, Residuals and R2,
+// describe the scaled problem. See Lstsq for the interpretation of tol.
+func WLS(a mat.Matrix, b mat.Vector, w []float64, tol float64) (*Fit, error) {
+	rows, _ := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if len(w) != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d weights", rows, len(w))
+	}
+	aw := mat.DenseCopyOf(a)
+	bw := mat.VecDenseCopyOf(b)
+	for i, v := range w {
+		if v < 0 {
+			return nil, fmt.Errorf("regress: negative weight: %v", v)
+		}
+		s := math.Sqrt(v)
+		row := aw.RawRowView(i)
+		for j := range row {
+			row[j] *= s
+		}
+		bw.SetVec(i, s*bw.AtVec(i))
+	}
+	return Lstsq(aw, bw, tol)
+}
+
+// GLS returns the generalized least squares fit of A x = b for
+// observation errors with covariance cov, minimizing
+// (b - A x)ᵀ cov⁻¹ (b - A x).
+//
+// The fit is computed by whitening the problem with the inverse of the
+// Cholesky factor of cov and all the diagnostics of the returned Fit
+// describe the whitened problem. See Lstsq for the interpretation of tol.
+func GLS(a mat.Matrix, b mat.Vector, cov mat.Symmetric, tol float64) (*Fit, error) {
+	rows, _ := a.Dims()
+	if b.Len() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d responses", rows, b.Len())
+	}
+	if cov.Symmetric() != rows {
+		return nil, fmt.Errorf("regress: %d observations but %d×%[2]d covariance", rows, cov.Symmetric())
+	}
+	var chol mat.Cholesky
+	ok := chol.Factorize(cov)
+	if !ok {
+		return nil, errors.New("regress: covariance is not positive definite")
+	}
+	var l mat.TriDense
+	chol.LTo(&l)
+
+	var aw mat.Dense
+	err := aw.Solve(&l, a)
+	if err != nil {
+		return nil, err
+	}
+	var bw mat.VecDense
+	err = bw.SolveVec(&l, b)
+	if err != nil {
+		return nil, err
+	}
+	return Lstsq(&aw, &bw, tol)
+}
@@ -0,0 +1,240 @@
+package regress
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestWLS(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a := randDense(20, 3, rnd)
+	b := mat.NewVecDense(20, nil)
+	for i := 0; i < 20; i++ {
+		b.SetVec(i, rnd.NormFloat64())
+	}
+
+	// Unit weights give ordinary least squares.
+	ones := make([]float64, 20)
+	floats.AddConst(1, ones)
+	wls, err := WLS(a, b, ones, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ols, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(wls.X, ols.X, 1e-12) {
+		t.Errorf("unexpected unit weight coefficients: got:%v want:%v", wls.X.RawVector().Data, ols.X.RawVector().Data)
+	}
+	if !floats.EqualApprox(wls.StdErr, ols.StdErr, 1e-12) {
+		t.Errorf("unexpected unit weight standard errors: got:%v want:%v", wls.StdErr, ols.StdErr)
+	}
+
+	// Integer weights are equivalent to repeating observations.
+	w := make([]float64, 20)
+	var rep []float64
+	var repB []float64
+	for i := range w {
+		w[i] = float64(1 + i%3)
+		for k := 0; k < int(w[i]); k++ {
+			rep = append(rep, a.RawRowView(i)...)
+			repB = append(repB, b.AtVec(i))
+		}
+	}
+	wls, err = WLS(a, b, w, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ols, err = Lstsq(mat.NewDense(len(repB), 3, rep), mat.NewVecDense(len(repB), repB), DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(wls.X, ols.X, 1e-12) {
+		t.Errorf("unexpected weighted coefficients: got:%v want:%v", wls.X.RawVector().Data, ols.X.RawVector().Data)
+	}
+
+	// A zero weight removes an observation.
+	w[0] = 0
+	wls, err = WLS(a, b, w, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w[0] = 1
+	b.SetVec(0, 1e6)
+	moved, err := WLS(a, b, w, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w[0] = 0
+	zeroed, err := WLS(a, b, w, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(zeroed.X, wls.X, 1e-12) || mat.EqualApprox(moved.X, wls.X, 1e-3) {
+		t.Error("zero weight does not remove observation")
+	}
+
+	for _, test := range []struct {
+		name string
+		b    mat.Vector
+		w    []float64
+	}{
+		{name: "short response", b: mat.NewVecDense(3, nil), w: ones},
+		{name: "short weights", b: b, w: ones[:3]},
+		{name: "negative weight", b: b, w: append([]float64{-1}, ones[1:]...)},
+	} {
+		_, err := WLS(a, test.b, test.w, DefaultTol)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestGLS(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const rows = 20
+	a := randDense(rows, 3, rnd)
+	b := mat.NewVecDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		b.SetVec(i, rnd.NormFloat64())
+	}
+
+	// A diagonal covariance gives WLS with inverse variance weights.
+	variance := make([]float64, rows)
+	w := make([]float64, rows)
+	for i := range variance {
+		variance[i] = 0.5 + rnd.Float64()
+		w[i] = 1 / variance[i]
+	}
+	gls, err := GLS(a, b, mat.NewDiagDense(rows, variance), DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wls, err := WLS(a, b, w, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(gls.X, wls.X, 1e-12) {
+		t.Errorf("unexpected diagonal covariance coefficients: got:%v want:%v", gls.X.RawVector().Data, wls.X.RawVector().Data)
+	}
+	if !floats.EqualApprox(gls.StdErr, wls.StdErr, 1e-12) {
+		t.Errorf("unexpected diagonal covariance standard errors: got:%v want:%v", gls.StdErr, wls.StdErr)
+	}
+
+	// A full covariance gives the solution of the generalized
+	// normal equations, (Aᵀ C⁻¹ A) x = Aᵀ C⁻¹ b.
+	const phi = 0.7
+	cov := mat.NewSymDense(rows, nil)
+	for i := 0; i < rows; i++ {
+		for j := i; j < rows; j++ {
+			cov.SetSym(i, j, math.Pow(phi, float64(j-i)))
+		}
+	}
+	gls, err = GLS(a, b, cov, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ci, atci, lhs mat.Dense
+	err = ci.Inverse(cov)
+	if err != nil {
+		t.Fatalf("unexpected error inverting covariance: %v", err)
+	}
+	atci.Mul(a.T(), &ci)
+	lhs.Mul(&atci, a)
+	var rhs, want mat.VecDense
+	rhs.MulVec(&atci, b)
+	err = want.SolveVec(&lhs, &rhs)
+	if err != nil {
+		t.Fatalf("unexpected error solving normal equations: %v", err)
+	}
+	if !mat.EqualApprox(gls.X, &want, 1e-10) {
+		t.Errorf("unexpected correlated covariance coefficients: got:%v want:%v", gls.X.RawVector().Data, want.RawVector().Data)
+	}
+
+	for _, test := range []struct {
+		name string
+		b    mat.Vector
+		cov  mat.Symmetric
+	}{
+		{name: "short response", b: mat.NewVecDense(3, nil), cov: cov},
+		{name: "small covariance", b: b, cov: mat.NewDiagDense(3, []float64{1, 1, 1})},
+		{name: "indefinite covariance", b: b, cov: mat.NewDiagDense(rows, make([]float64, rows))},
+	} {
+		_, err := GLS(a, test.b, test.cov, DefaultTol)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestTLS(t *testing.T) {
+	// Errors of equal variance in both variables bias the
+	// least squares slope towards zero, but not the total
+	// least squares slope.
+	const (
+		n     = 2000
+		slope = 2.0
+		sigma = 0.5
+	)
+	rnd := rand.New(rand.NewSource(1))
+	a := mat.NewVecDense(n, nil)
+	b := mat.NewVecDense(n, nil)
+	var sxx float64
+	for i := 0; i < n; i++ {
+		x := 4*rnd.Float64() - 2
+		sxx += x * x
+		a.SetVec(i, x+sigma*rnd.NormFloat64())
+		b.SetVec(i, slope*x+sigma*rnd.NormFloat64())
+	}
+	tls, err := TLS(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tls.AtVec(0); !scalar.EqualWithinAbs(got, slope, 0.05) {
+		t.Errorf("unexpected TLS slope: got:%v want:%v", got, slope)
+	}
+	ols, err := Lstsq(a, b, DefaultTol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The expected least squares slope is attenuated by the
+	// reliability ratio var(x)/(var(x)+σ²).
+	varX := sxx / n
+	attenuated := slope * varX / (varX + sigma*sigma)
+	if got := ols.X.AtVec(0); !scalar.EqualWithinAbs(got, attenuated, 0.05) {
+		t.Errorf("unexpected OLS slope: got:%v want:%v", got, attenuated)
+	}
+
+	// Exact data is recovered exactly.
+	a2 := randDense(10, 2, rnd)
+	want := mat.NewVecDense(2, []float64{-1, 0.5})
+	var b2 mat.VecDense
+	b2.MulVec(a2, want)
+	got, err := TLS(a2, &b2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mat.EqualApprox(got, want, 1e-12) {
+		t.Errorf("unexpected exact TLS solution: got:%v want:%v", got.RawVector().Data, want.RawVector().Data)
+	}
+
+	// A response orthogonal to and larger than the columns
+	// has no solution.
+	a3 := mat.NewDense(3, 1, []float64{1, 0, 0})
+	b3 := mat.NewVecDense(3, []float64{0, 2, 0})
+	_, err = TLS(a3, b3)
+	if err != ErrNonGeneric {
+		t.Errorf("unexpected error for non-generic problem: got:%v want:%v", err, ErrNonGeneric)
+	}
+
+	_, err = TLS(a2.Slice(0, 2, 0, 2), mat.NewVecDense(2, nil))
+	if err == nil {
+		t.Error("expected error for too few observations")
+	}
+}
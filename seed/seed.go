@@ -0,0 +1,45 @@
+// Package seed provides the random source used by the demos so that
+// regenerated notebooks are reproducible.
+//
+// The seed is taken from the DATABOOK_SEED environment variable if it is
+// set, and otherwise Default is used. The environment is only consulted
+// by the demos; the library packages use a fixed source when none is
+// provided.
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+)
+
+// Default is the seed used when none is specified.
+const Default = 1
+
+// Env is the environment variable holding the seed.
+const Env = "DATABOOK_SEED"
+
+// Value returns the seed specified by the DATABOOK_SEED environment
+// variable, or Default if it is not set. Value returns an error if the
+// environment variable is set but is not a valid integer.
+func Value() (int64, error) {
+	env, ok := os.LookupEnv(Env)
+	if !ok {
+		return Default, nil
+	}
+	v, err := strconv.ParseInt(env, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("seed: invalid %s: %w", Env, err)
+	}
+	return v, nil
+}
+
+// Rand returns a new random source seeded with Value.
+func Rand() (*rand.Rand, error) {
+	v, err := Value()
+	if err != nil {
+		return nil, err
+	}
+	return rand.New(rand.NewSource(v)), nil
+}
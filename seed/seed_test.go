@@ -0,0 +1,69 @@
+package seed
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	old, ok := os.LookupEnv(Env)
+	defer func() {
+		if ok {
+			os.Setenv(Env, old)
+		} else {
+			os.Unsetenv(Env)
+		}
+	}()
+
+	os.Unsetenv(Env)
+	got, err := Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != Default {
+		t.Errorf("unexpected default seed: got:%d want:%d", got, Default)
+	}
+	for _, test := range []struct {
+		env  string
+		want int64
+	}{
+		{env: "0", want: 0},
+		{env: "42", want: 42},
+		{env: "-7", want: -7},
+	} {
+		os.Setenv(Env, test.env)
+		got, err := Value()
+		if err != nil {
+			t.Errorf("unexpected error for %s=%q: %v", Env, test.env, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("unexpected seed for %s=%q: got:%d want:%d", Env, test.env, got, test.want)
+		}
+	}
+
+	os.Setenv(Env, "42")
+	a, err := Rand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := Rand()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("unexpected difference between sources at %d: %d != %d", i, x, y)
+		}
+	}
+
+	for _, env := range []string{"one", "", "1.5", "99999999999999999999"} {
+		os.Setenv(Env, env)
+		if _, err := Value(); err == nil {
+			t.Errorf("expected error for %s=%q", Env, env)
+		}
+		if rnd, err := Rand(); err == nil || rnd != nil {
+			t.Errorf("expected error and nil source for %s=%q", Env, env)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package spectral
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Deriv places the spectral derivative of the given order of the periodic
+// samples x into dst and returns it. The samples are taken at equal
+// spacing over a period of the given length, with the first sample at the
+// start of the period. If dst is nil, a new slice is allocated. If dst is
+// not nil and the length of dst does not equal the length of x, Deriv will
+// panic. It is safe to use the same slice for dst and x.
+//
+// For odd orders, the Nyquist mode of an even number of samples is set to
+// zero since its derivative is not resolved at the sample points. For even
+// orders the Nyquist mode is retained.
+//
+// If dealias is true, modes with wavenumber magnitudes greater than one
+// third of the number of samples are removed following the 2/3 rule.
+func Deriv(dst, x []float64, length float64, order int, dealias bool) []float64 {
+	if order < 0 {
+		panic("spectral: negative derivative order")
+	}
+	n := len(x)
+	if dst == nil {
+		dst = make([]float64, n)
+	} else if len(dst) != n {
+		panic("spectral: destination length mismatch")
+	}
+	if n == 0 {
+		return dst
+	}
+	fft := fourier.NewFFT(n)
+	coeff := fft.Coefficients(nil, x)
+	for k := range coeff {
+		coeff[k] *= multiplier(k, n, length, order, dealias) / complex(float64(n), 0)
+	}
+	return fft.Sequence(dst, coeff)
+}
+
+// Deriv2 places the spectral partial derivative ∂ᵖ⁺ᵠm/∂xᵖ∂yᵠ of the
+// periodic matrix m into dst and returns it, where p and q are xOrder and
+// yOrder. The x coordinate increases along each row of m over a period of
+// length lx and the y coordinate increases down each column over a period
+// of length ly, with m[0, 0] at the start of both periods. If dst is nil,
+// a new matrix is allocated. If dst is not nil and does not have the same
+// dimensions as m, Deriv2 will panic.
+//
+// The Nyquist modes and dealiasing are handled in each direction as
+// described for Deriv.
+func Deriv2(dst *mat.Dense, m mat.Matrix, lx, ly float64, xOrder, yOrder int, dealias bool) *mat.Dense {
+	if xOrder < 0 || yOrder < 0 {
+		panic("spectral: negative derivative order")
+	}
+	r, c := m.Dims()
+	if r == 0 || c == 0 {
+		if dst == nil {
+			return &mat.Dense{}
+		}
+		if dr, dc := dst.Dims(); dr != r || dc != c {
+			panic("spectral: destination dimension mismatch")
+		}
+		return dst
+	}
+	fft := NewFFT2(r, c)
+	coeff := fft.Coefficients(nil, m)
+	norm := complex(float64(r*c), 0)
+	for i := 0; i < r; i++ {
+		ky := multiplier(i, r, ly, yOrder, dealias)
+		for j := 0; j <= c/2; j++ {
+			kx := multiplier(j, c, lx, xOrder, dealias)
+			coeff.Set(i, j, coeff.At(i, j)*kx*ky/norm)
+		}
+	}
+	return fft.Sequence(dst, coeff)
+}
+
+// multiplier returns (i2πk/length)^order for the wavenumber k of the
+// Fourier coefficient with index i of n samples, handling the Nyquist mode
+// and dealiasing as described for Deriv.
+func multiplier(i, n int, length float64, order int, dealias bool) complex128 {
+	k := i
+	if k > n/2 {
+		k -= n
+	}
+	if dealias && 3*abs(k) > n {
+		return 0
+	}
+	if order == 0 {
+		return 1
+	}
+	if 2*i == n && order%2 == 1 {
+		return 0
+	}
+	kappa := 2 * math.Pi * float64(k) / length
+	m := complex(math.Pow(kappa, float64(order)), 0)
+	// Multiply by iᵒʳᵈᵉʳ.
+	switch order % 4 {
+	case 1:
+		m *= 1i
+	case 2:
+		m = -m
+	case 3:
+		m *= -1i
+	}
+	return m
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,204 @@
+package spectral
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDeriv(t *testing.T) {
+	const (
+		length = 3.0
+		k      = 3
+	)
+	kappa := 2 * math.Pi * k / length
+	for _, n := range []int{32, 33} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = math.Sin(kappa * float64(i) * length / float64(n))
+		}
+		for _, test := range []struct {
+			order int
+			want  func(float64) float64
+		}{
+			{order: 0, want: func(s float64) float64 { return math.Sin(kappa * s) }},
+			{order: 1, want: func(s float64) float64 { return kappa * math.Cos(kappa*s) }},
+			{order: 2, want: func(s float64) float64 { return -kappa * kappa * math.Sin(kappa*s) }},
+			{order: 3, want: func(s float64) float64 { return -kappa * kappa * kappa * math.Cos(kappa*s) }},
+		} {
+			want := make([]float64, n)
+			for i := range want {
+				want[i] = test.want(float64(i) * length / float64(n))
+			}
+			got := Deriv(nil, x, length, test.order, false)
+			if !floats.EqualApprox(got, want, 1e-11) {
+				t.Errorf("unexpected derivative of order %d for n=%d:\ngot: %v\nwant:%v", test.order, n, got, want)
+			}
+
+			// The same slice can be used for dst and x.
+			y := append([]float64(nil), x...)
+			if got := Deriv(y, y, length, test.order, false); !floats.EqualApprox(got, want, 1e-11) {
+				t.Errorf("unexpected derivative of order %d for n=%d with aliased dst", test.order, n)
+			}
+		}
+	}
+}
+
+func TestDerivNyquist(t *testing.T) {
+	const (
+		n      = 8
+		length = 2.0
+	)
+	// The alternating sequence is the Nyquist mode.
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = math.Cos(math.Pi * float64(i))
+	}
+	kappa := math.Pi * n / length
+	for order := 1; order <= 4; order++ {
+		got := Deriv(nil, x, length, order, false)
+		want := make([]float64, n)
+		if order%2 == 0 {
+			floats.ScaleTo(want, math.Pow(-kappa*kappa, float64(order/2)), x)
+		}
+		if !floats.EqualApprox(got, want, 1e-10) {
+			t.Errorf("unexpected derivative of Nyquist mode of order %d:\ngot: %v\nwant:%v", order, got, want)
+		}
+	}
+}
+
+func TestDerivDealias(t *testing.T) {
+	const (
+		n      = 12
+		length = 1.0
+	)
+	// Wavenumber 4 is at the dealiasing limit of n/3 and is
+	// kept, while wavenumbers 5 and 6 are removed.
+	x := make([]float64, n)
+	kept := make([]float64, n)
+	for i := range x {
+		s := 2 * math.Pi * float64(i) / n
+		kept[i] = 1 + math.Sin(4*s)
+		x[i] = kept[i] + math.Sin(5*s) + math.Cos(6*s)
+	}
+	for order := 0; order <= 2; order++ {
+		got := Deriv(nil, x, length, order, true)
+		want := Deriv(nil, kept, length, order, false)
+		if !floats.EqualApprox(got, want, 1e-10) {
+			t.Errorf("unexpected dealiased derivative of order %d:\ngot: %v\nwant:%v", order, got, want)
+		}
+	}
+	if got := Deriv(nil, x, length, 0, false); !floats.EqualApprox(got, x, 1e-14) {
+		t.Errorf("unexpected zero order derivative without dealiasing:\ngot: %v\nwant:%v", got, x)
+	}
+}
+
+func TestDeriv2(t *testing.T) {
+	const (
+		lx = 2.0
+		ly = 3.0
+		a  = 2
+		b  = 1
+	)
+	kx := 2 * math.Pi * a / lx
+	ky := 2 * math.Pi * b / ly
+	for _, d := range []struct{ r, c int }{{r: 12, c: 10}, {r: 9, c: 7}} {
+		m := mat.NewDense(d.r, d.c, nil)
+		for i := 0; i < d.r; i++ {
+			for j := 0; j < d.c; j++ {
+				x := float64(j) * lx / float64(d.c)
+				y := float64(i) * ly / float64(d.r)
+				m.Set(i, j, math.Sin(kx*x)*math.Cos(ky*y))
+			}
+		}
+		for _, test := range []struct {
+			xOrder, yOrder int
+			want           func(x, y float64) float64
+		}{
+			{xOrder: 0, yOrder: 0, want: func(x, y float64) float64 { return math.Sin(kx*x) * math.Cos(ky*y) }},
+			{xOrder: 1, yOrder: 0, want: func(x, y float64) float64 { return kx * math.Cos(kx*x) * math.Cos(ky*y) }},
+			{xOrder: 0, yOrder: 2, want: func(x, y float64) float64 { return -ky * ky * math.Sin(kx*x) * math.Cos(ky*y) }},
+			{xOrder: 1, yOrder: 1, want: func(x, y float64) float64 { return -kx * ky * math.Cos(kx*x) * math.Sin(ky*y) }},
+			{xOrder: 2, yOrder: 1, want: func(x, y float64) float64 { return kx * kx * ky * math.Sin(kx*x) * math.Sin(ky*y) }},
+		} {
+			want := mat.NewDense(d.r, d.c, nil)
+			for i := 0; i < d.r; i++ {
+				for j := 0; j < d.c; j++ {
+					want.Set(i, j, test.want(float64(j)*lx/float64(d.c), float64(i)*ly/float64(d.r)))
+				}
+			}
+			got := Deriv2(nil, m, lx, ly, test.xOrder, test.yOrder, false)
+			if !mat.EqualApprox(got, want, 1e-11) {
+				t.Errorf("unexpected derivative ∂^%d/∂x^%d∂y^%d for %d×%d:\ngot: %v\nwant:%v",
+					test.xOrder+test.yOrder, test.xOrder, test.yOrder, d.r, d.c, mat.Formatted(got), mat.Formatted(want))
+			}
+		}
+	}
+}
+
+func TestDeriv2Rows(t *testing.T) {
+	// Derivatives along x agree with Deriv applied to each row,
+	// including the handling of the Nyquist mode and dealiasing.
+	rnd := rand.New(rand.NewSource(1))
+	const lx = 5.0
+	for _, d := range []struct{ r, c int }{{r: 4, c: 12}, {r: 5, c: 9}} {
+		m := randMatrix(d.r, d.c, rnd)
+		for _, dealias := range []bool{false, true} {
+			for order := 0; order <= 3; order++ {
+				got := Deriv2(nil, m, lx, 1, order, 0, dealias)
+				for i := 0; i < d.r; i++ {
+					row := mat.Row(nil, i, m)
+					if dealias {
+						// Dealiasing removes the same modes
+						// along y as the zero order x derivative.
+						row = mat.Row(nil, i, Deriv2(nil, m, lx, 1, 0, 0, true))
+					}
+					want := Deriv(nil, row, lx, order, dealias)
+					if !floats.EqualApprox(mat.Row(nil, i, got), want, 1e-10) {
+						t.Errorf("unexpected row %d of x derivative of order %d for %d×%d with dealias=%t",
+							i, order, d.r, d.c, dealias)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDerivEmpty(t *testing.T) {
+	if got := Deriv(nil, nil, 1, 1, false); len(got) != 0 {
+		t.Errorf("unexpected derivative of empty input: %v", got)
+	}
+	if got := Deriv([]float64{}, []float64{}, 1, 2, true); len(got) != 0 {
+		t.Errorf("unexpected derivative of empty input: %v", got)
+	}
+	got := Deriv2(nil, &mat.Dense{}, 1, 1, 1, 1, false)
+	if got == nil || !got.IsEmpty() {
+		t.Errorf("unexpected derivative of empty matrix: %v", got)
+	}
+	dst := &mat.Dense{}
+	if got := Deriv2(dst, &mat.Dense{}, 1, 1, 1, 0, true); got != dst {
+		t.Error("Deriv2 did not return dst for empty matrix")
+	}
+}
+
+func TestDerivPanics(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fn   func()
+	}{
+		{name: "negative order", fn: func() { Deriv(nil, make([]float64, 4), 1, -1, false) }},
+		{name: "dst length", fn: func() { Deriv(make([]float64, 3), make([]float64, 4), 1, 1, false) }},
+		{name: "empty dst length", fn: func() { Deriv(make([]float64, 3), nil, 1, 1, false) }},
+		{name: "negative x order", fn: func() { Deriv2(nil, mat.NewDense(4, 4, nil), 1, 1, -1, 0, false) }},
+		{name: "negative y order", fn: func() { Deriv2(nil, mat.NewDense(4, 4, nil), 1, 1, 0, -1, false) }},
+		{name: "dst dimensions", fn: func() { Deriv2(mat.NewDense(4, 3, nil), mat.NewDense(4, 4, nil), 1, 1, 1, 0, false) }},
+		{name: "empty dst dimensions", fn: func() { Deriv2(mat.NewDense(4, 3, nil), &mat.Dense{}, 1, 1, 1, 0, false) }},
+	} {
+		if !panics(test.fn) {
+			t.Errorf("expected panic for %s", test.name)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package spectral provides Fourier analysis of signals and images.
+package spectral
+
+import (
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FFT2 implements the two-dimensional Fast Fourier Transform and its
+// inverse for real matrices.
+//
+// The transform of an r×c matrix is held in an r×(c/2+1) complex matrix.
+// The real transform is applied along each row and then the complex
+// transform along each column of the result. The remaining coefficients
+// are given by conjugate symmetry, X[i, j] = conj(X[(r-i)%r, (c-j)%c]).
+type FFT2 struct {
+	rows, cols int
+
+	row *fourier.FFT
+	col *fourier.CmplxFFT
+
+	seq   []float64
+	coeff []complex128
+	work  []complex128
+}
+
+// NewFFT2 returns an FFT2 initialized for work on r×c matrices.
+func NewFFT2(r, c int) *FFT2 {
+	return &FFT2{
+		rows:  r,
+		cols:  c,
+		row:   fourier.NewFFT(c),
+		col:   fourier.NewCmplxFFT(r),
+		seq:   make([]float64, c),
+		coeff: make([]complex128, c/2+1),
+		work:  make([]complex128, r),
+	}
+}
+
+// Dims returns the dimensions of the acceptable input.
+func (t *FFT2) Dims() (r, c int) { return t.rows, t.cols }
+
+// Coefficients computes the Fourier coefficients of the matrix m, placing
+// the result in dst and returning it. The transform is unnormalized; a call
+// to Coefficients followed by a call of Sequence will multiply the input by
+// the number of elements in the matrix.
+//
+// If the dimensions of m are not t.Dims(), Coefficients will panic. If dst
+// is nil, a new matrix is allocated and returned. If dst is not nil and
+// is not r×(c/2+1), Coefficients will panic.
+func (t *FFT2) Coefficients(dst *mat.CDense, m mat.Matrix) *mat.CDense {
+	r, c := m.Dims()
+	if r != t.rows || c != t.cols {
+		panic("spectral: matrix dimension mismatch")
+	}
+	dst = t.reuseCoeff(dst)
+	for i := 0; i < r; i++ {
+		mat.Row(t.seq, i, m)
+		t.row.Coefficients(t.coeff, t.seq)
+		for j, v := range t.coeff {
+			dst.Set(i, j, v)
+		}
+	}
+	t.columns(dst, t.col.Coefficients)
+	return dst
+}
+
+// Sequence computes the real matrix from the Fourier coefficients in coeff,
+// placing the result in dst and returning it. The transform is
+// unnormalized; a call to Coefficients followed by a call of Sequence will
+// multiply the input by the number of elements in the matrix. The imaginary
+// parts of coefficients that must be real by conjugate symmetry are
+// ignored.
+//
+// If coeff is not r×(c/2+1), Sequence will panic. If dst is nil, a new
+// matrix is allocated and returned. If dst is not nil and is not r×c,
+// Sequence will panic. Sequence does not modify coeff.
+func (t *FFT2) Sequence(dst *mat.Dense, coeff *mat.CDense) *mat.Dense {
+	r, c := coeff.Dims()
+	if r != t.rows || c != t.cols/2+1 {
+		panic("spectral: coefficient dimension mismatch")
+	}
+	if dst == nil {
+		dst = mat.NewDense(t.rows, t.cols, nil)
+	} else if r, c := dst.Dims(); r != t.rows || c != t.cols {
+		panic("spectral: destination dimension mismatch")
+	}
+	tmp := mat.NewCDense(r, c, nil)
+	tmp.Copy(coeff)
+	t.columns(tmp, t.col.Sequence)
+	for i := 0; i < t.rows; i++ {
+		for j := range t.coeff {
+			t.coeff[j] = tmp.At(i, j)
+		}
+		t.row.Sequence(t.seq, t.coeff)
+		dst.SetRow(i, t.seq)
+	}
+	return dst
+}
+
+// columns applies fn in place to each column of m.
+func (t *FFT2) columns(m *mat.CDense, fn func(dst, src []complex128) []complex128) {
+	_, c := m.Dims()
+	for j := 0; j < c; j++ {
+		for i := range t.work {
+			t.work[i] = m.At(i, j)
+		}
+		fn(t.work, t.work)
+		for i, v := range t.work {
+			m.Set(i, j, v)
+		}
+	}
+}
+
+func (t *FFT2) reuseCoeff(dst *mat.CDense) *mat.CDense {
+	if dst == nil {
+		return mat.NewCDense(t.rows, t.cols/2+1, nil)
+	}
+	if r, c := dst.Dims(); r != t.rows || c != t.cols/2+1 {
+		panic("spectral: destination dimension mismatch")
+	}
+	return dst
+}
+
+// Magnitude places the magnitudes of the full r×c spectrum described by
+// coeff into dst, shifted so that the zero frequency is at row r/2 and
+// column c/2, and returns it. If dst is nil, a new matrix is allocated and
+// returned. If dst is not nil and is not r×c, Magnitude will panic.
+func (t *FFT2) Magnitude(dst *mat.Dense, coeff *mat.CDense) *mat.Dense {
+	r, c := coeff.Dims()
+	if r != t.rows || c != t.cols/2+1 {
+		panic("spectral: coefficient dimension mismatch")
+	}
+	if dst == nil {
+		dst = mat.NewDense(t.rows, t.cols, nil)
+	} else if r, c := dst.Dims(); r != t.rows || c != t.cols {
+		panic("spectral: destination dimension mismatch")
+	}
+	for i := 0; i < t.rows; i++ {
+		for j := 0; j < t.cols; j++ {
+			var v complex128
+			if j < c {
+				v = coeff.At(i, j)
+			} else {
+				v = coeff.At((t.rows-i)%t.rows, t.cols-j)
+			}
+			dst.Set((i+t.rows/2)%t.rows, (j+t.cols/2)%t.cols, cmplx.Abs(v))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,204 @@
+package spectral
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+)
+
+var fft2Dims = []struct{ r, c int }{
+	{r: 4, c: 6},
+	{r: 5, c: 7},
+	{r: 4, c: 7},
+	{r: 5, c: 6},
+	{r: 1, c: 8},
+	{r: 3, c: 1},
+}
+
+func randMatrix(r, c int, rnd *rand.Rand) *mat.Dense {
+	m := mat.NewDense(r, c, nil)
+	m.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, m)
+	return m
+}
+
+// fullFFT2 returns the full two-dimensional transform of m computed with
+// complex transforms along both axes.
+func fullFFT2(m mat.Matrix) *mat.CDense {
+	r, c := m.Dims()
+	dst := mat.NewCDense(r, c, nil)
+	row := fourier.NewCmplxFFT(c)
+	seq := make([]complex128, c)
+	for i := 0; i < r; i++ {
+		for j := range seq {
+			seq[j] = complex(m.At(i, j), 0)
+		}
+		row.Coefficients(seq, seq)
+		for j, v := range seq {
+			dst.Set(i, j, v)
+		}
+	}
+	col := fourier.NewCmplxFFT(r)
+	seq = make([]complex128, r)
+	for j := 0; j < c; j++ {
+		for i := range seq {
+			seq[i] = dst.At(i, j)
+		}
+		col.Coefficients(seq, seq)
+		for i, v := range seq {
+			dst.Set(i, j, v)
+		}
+	}
+	return dst
+}
+
+func TestFFT2RoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, d := range fft2Dims {
+		m := randMatrix(d.r, d.c, rnd)
+		fft := NewFFT2(d.r, d.c)
+		if r, c := fft.Dims(); r != d.r || c != d.c {
+			t.Errorf("unexpected dimensions: got:%d×%d want:%d×%d", r, c, d.r, d.c)
+		}
+		coeff := fft.Coefficients(nil, m)
+		if r, c := coeff.Dims(); r != d.r || c != d.c/2+1 {
+			t.Errorf("unexpected coefficient dimensions for %d×%d: got:%d×%d want:%d×%d", d.r, d.c, r, c, d.r, d.c/2+1)
+		}
+		saved := mat.NewCDense(d.r, d.c/2+1, nil)
+		saved.Copy(coeff)
+
+		got := fft.Sequence(nil, coeff)
+		var want mat.Dense
+		want.Scale(float64(d.r*d.c), m)
+		if !mat.EqualApprox(got, &want, 1e-12) {
+			t.Errorf("unexpected round trip for %d×%d:\ngot: %v\nwant:%v", d.r, d.c, mat.Formatted(got), mat.Formatted(&want))
+		}
+		if !mat.CEqual(coeff, saved) {
+			t.Errorf("Sequence modified coefficients for %d×%d", d.r, d.c)
+		}
+
+		// Destinations are reused.
+		dst := mat.NewDense(d.r, d.c, nil)
+		if fft.Sequence(dst, coeff) != dst {
+			t.Errorf("Sequence did not return dst for %d×%d", d.r, d.c)
+		}
+		cdst := mat.NewCDense(d.r, d.c/2+1, nil)
+		if fft.Coefficients(cdst, m) != cdst || !mat.CEqual(cdst, saved) {
+			t.Errorf("Coefficients did not fill dst for %d×%d", d.r, d.c)
+		}
+	}
+}
+
+func TestFFT2Full(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, d := range fft2Dims {
+		m := randMatrix(d.r, d.c, rnd)
+		fft := NewFFT2(d.r, d.c)
+		coeff := fft.Coefficients(nil, m)
+		full := fullFFT2(m)
+
+		for i := 0; i < d.r; i++ {
+			for j := 0; j < d.c; j++ {
+				want := full.At(i, j)
+				var got complex128
+				if j <= d.c/2 {
+					got = coeff.At(i, j)
+				} else {
+					// The remaining coefficients are given by
+					// conjugate symmetry.
+					got = cmplx.Conj(coeff.At((d.r-i)%d.r, d.c-j))
+				}
+				if cmplx.Abs(got-want) > 1e-12 {
+					t.Errorf("unexpected coefficient (%d, %d) for %d×%d: got:%v want:%v", i, j, d.r, d.c, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFFT2Magnitude(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, d := range fft2Dims {
+		m := randMatrix(d.r, d.c, rnd)
+		fft := NewFFT2(d.r, d.c)
+		mag := fft.Magnitude(nil, fft.Coefficients(nil, m))
+		full := fullFFT2(m)
+
+		// The zero frequency term, the sum of the elements,
+		// is at the centre.
+		var sum float64
+		for i := 0; i < d.r; i++ {
+			for j := 0; j < d.c; j++ {
+				sum += m.At(i, j)
+			}
+		}
+		if got := mag.At(d.r/2, d.c/2); !scalar.EqualWithinAbsOrRel(got, math.Abs(sum), 1e-12, 1e-12) {
+			t.Errorf("unexpected zero frequency magnitude for %d×%d: got:%v want:%v", d.r, d.c, got, sum)
+		}
+
+		for i := 0; i < d.r; i++ {
+			for j := 0; j < d.c; j++ {
+				got := mag.At((i+d.r/2)%d.r, (j+d.c/2)%d.c)
+				want := cmplx.Abs(full.At(i, j))
+				if !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+					t.Errorf("unexpected magnitude (%d, %d) for %d×%d: got:%v want:%v", i, j, d.r, d.c, got, want)
+				}
+			}
+		}
+	}
+
+	// A pure horizontal frequency appears at equal distances either
+	// side of the centre along the central row.
+	const r, c, k = 6, 8, 3
+	m := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			m.Set(i, j, math.Cos(2*math.Pi*k*float64(j)/c))
+		}
+	}
+	fft := NewFFT2(r, c)
+	mag := fft.Magnitude(nil, fft.Coefficients(nil, m))
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			want := 0.0
+			if i == r/2 && (j == c/2-k || j == c/2+k) {
+				want = r * c / 2
+			}
+			if !scalar.EqualWithinAbsOrRel(mag.At(i, j), want, 1e-12, 1e-12) {
+				t.Errorf("unexpected magnitude of cosine at (%d, %d): got:%v want:%v", i, j, mag.At(i, j), want)
+			}
+		}
+	}
+}
+
+func TestFFT2Panics(t *testing.T) {
+	fft := NewFFT2(4, 6)
+	good := mat.NewCDense(4, 4, nil)
+	for _, test := range []struct {
+		name string
+		fn   func()
+	}{
+		{name: "Coefficients input", fn: func() { fft.Coefficients(nil, mat.NewDense(4, 5, nil)) }},
+		{name: "Coefficients dst", fn: func() { fft.Coefficients(mat.NewCDense(4, 3, nil), mat.NewDense(4, 6, nil)) }},
+		{name: "Sequence input", fn: func() { fft.Sequence(nil, mat.NewCDense(3, 4, nil)) }},
+		{name: "Sequence dst", fn: func() { fft.Sequence(mat.NewDense(4, 4, nil), good) }},
+		{name: "Magnitude input", fn: func() { fft.Magnitude(nil, mat.NewCDense(4, 3, nil)) }},
+		{name: "Magnitude dst", fn: func() { fft.Magnitude(mat.NewDense(6, 4, nil), good) }},
+	} {
+		if !panics(test.fn) {
+			t.Errorf("expected panic for %s", test.name)
+		}
+	}
+}
+
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	fn()
+	return false
+}
@@ -0,0 +1,215 @@
+package spectral
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// Filter is a zero-phase frequency-domain filter for real signals.
+type Filter interface {
+	// Gains places the gains to apply to the
+	// Fourier coefficients of a signal with the
+	// periodogram p into dst and returns it. The
+	// gains correspond to the frequencies in p.Freq.
+	Gains(dst []float64, p *PSD) []float64
+}
+
+// Apply filters the signal x sampled at rate fs with f, placing the result
+// in dst and returning it. If dst is nil, a new slice is allocated. If dst
+// is not nil and the length of dst does not equal the length of x, Apply
+// will panic. It is safe to use the same slice for dst and x.
+//
+// The signal is transformed with a real FFT so only the zero to Nyquist
+// frequencies are filtered; the negative frequencies are filtered by
+// conjugate symmetry. The zero frequency and, for signals with an even
+// number of samples, the Nyquist frequency have real coefficients that
+// are scaled by their real gains.
+func Apply(dst, x []float64, fs float64, f Filter) []float64 {
+	n := len(x)
+	if dst == nil {
+		dst = make([]float64, n)
+	} else if len(dst) != n {
+		panic("spectral: destination length mismatch")
+	}
+	fft := fourier.NewFFT(n)
+	coeff := fft.Coefficients(nil, x)
+	gains := f.Gains(make([]float64, len(coeff)), periodogram(coeff, n, fs, float64(n)))
+	for i, g := range gains {
+		coeff[i] *= complex(g/float64(n), 0)
+	}
+	return fft.Sequence(dst, coeff)
+}
+
+// Response is a filter whose gain depends only on frequency.
+type Response func(f float64) float64
+
+// Gains implements the Filter interface.
+func (r Response) Gains(dst []float64, p *PSD) []float64 {
+	for i, f := range p.Freq {
+		dst[i] = r(f)
+	}
+	return dst
+}
+
+// Lowpass is a low-pass filter passing frequencies below Cutoff Hz. If
+// Order is zero, the filter is ideal, otherwise it has the magnitude
+// response of a Butterworth filter of the given order, with a gain of
+// 1/√2 at Cutoff.
+type Lowpass struct {
+	Cutoff float64
+	Order  int
+}
+
+// Gains implements the Filter interface.
+func (l Lowpass) Gains(dst []float64, p *PSD) []float64 {
+	return Response(func(f float64) float64 {
+		return butterworth(f/l.Cutoff, l.Order)
+	}).Gains(dst, p)
+}
+
+// Highpass is a high-pass filter passing frequencies above Cutoff Hz. If
+// Order is zero, the filter is ideal, otherwise it has the magnitude
+// response of a Butterworth filter of the given order, with a gain of
+// 1/√2 at Cutoff.
+type Highpass struct {
+	Cutoff float64
+	Order  int
+}
+
+// Gains implements the Filter interface.
+func (h Highpass) Gains(dst []float64, p *PSD) []float64 {
+	return Response(func(f float64) float64 {
+		return butterworth(h.Cutoff/f, h.Order)
+	}).Gains(dst, p)
+}
+
+// Bandpass is a band-pass filter passing frequencies between Low and High
+// Hz. If Order is zero, the filter is ideal, otherwise it has the
+// magnitude response of a Butterworth filter of the given order, with a
+// gain of 1/√2 at Low and High.
+type Bandpass struct {
+	Low, High float64
+	Order     int
+}
+
+// Gains implements the Filter interface.
+func (b Bandpass) Gains(dst []float64, p *PSD) []float64 {
+	return Response(func(f float64) float64 {
+		return butterworth(bandRatio(f, b.Low, b.High), b.Order)
+	}).Gains(dst, p)
+}
+
+// Notch is a band-stop filter rejecting frequencies between Low and High
+// Hz. If Order is zero, the filter is ideal, otherwise it has the
+// magnitude response of a Butterworth filter of the given order, with a
+// gain of 1/√2 at Low and High and zero gain at √(Low·High).
+type Notch struct {
+	Low, High float64
+	Order     int
+}
+
+// Gains implements the Filter interface.
+func (n Notch) Gains(dst []float64, p *PSD) []float64 {
+	return Response(func(f float64) float64 {
+		return butterworth(1/bandRatio(f, n.Low, n.High), n.Order)
+	}).Gains(dst, p)
+}
+
+// bandRatio returns the normalized frequency of f for the Butterworth
+// low-pass to band-pass transformation with band edges low and high.
+// The ratio has magnitude 1 at the band edges and 0 at the center.
+func bandRatio(f, low, high float64) float64 {
+	return math.Abs(f*f-low*high) / (f * (high - low))
+}
+
+// butterworth returns the magnitude response of a Butterworth low-pass
+// filter of the given order at the normalized frequency r, with r = 1 at
+// the cutoff. If order is zero, the ideal response is returned.
+func butterworth(r float64, order int) float64 {
+	r = math.Abs(r)
+	if order == 0 {
+		if r <= 1 {
+			return 1
+		}
+		return 0
+	}
+	if math.IsInf(r, 1) {
+		return 0
+	}
+	return 1 / math.Sqrt(1+math.Pow(r, 2*float64(order)))
+}
+
+// PSDThreshold is a filter removing all frequencies where the one-sided
+// periodogram of the signal is not greater than Level, in squared signal
+// units per Hz. Level is typically obtained from the Threshold method of
+// a periodogram.
+//
+// The level at the zero and Nyquist frequencies is halved since the
+// periodogram at those frequencies does not include the power of a
+// negative frequency.
+type PSDThreshold struct {
+	Level float64
+}
+
+// Gains implements the Filter interface.
+func (t PSDThreshold) Gains(dst []float64, p *PSD) []float64 {
+	for i, v := range p.Power {
+		level := t.Level
+		if !p.folded(i) {
+			level /= 2
+		}
+		dst[i] = 0
+		if v > level {
+			dst[i] = 1
+		}
+	}
+	return dst
+}
+
+// Wiener is a Wiener filter for a signal in additive white noise with the
+// one-sided spectral density Noise, in squared signal units per Hz. The
+// gain at each frequency is the estimated fraction of the power due to the
+// signal, max(0, 1 - Noise/P), where P is the periodogram of the signal.
+// The noise level is typically obtained from the NoiseLevel method of a
+// periodogram.
+//
+// The noise density at the zero and Nyquist frequencies is taken to be
+// half of Noise since the periodogram at those frequencies does not
+// include the power of a negative frequency.
+type Wiener struct {
+	Noise float64
+}
+
+// Gains implements the Filter interface.
+func (w Wiener) Gains(dst []float64, p *PSD) []float64 {
+	for i, v := range p.Power {
+		noise := w.Noise
+		if !p.folded(i) {
+			noise /= 2
+		}
+		dst[i] = 0
+		if v > 0 {
+			dst[i] = math.Max(0, 1-noise/v)
+		}
+	}
+	return dst
+}
+
+// Cascade is a filter applying each of its filters in turn.
+type Cascade []Filter
+
+// Gains implements the Filter interface.
+func (c Cascade) Gains(dst []float64, p *PSD) []float64 {
+	for i := range dst {
+		dst[i] = 1
+	}
+	g := make([]float64, len(dst))
+	for _, f := range c {
+		f.Gains(g, p)
+		for i, v := range g {
+			dst[i] *= v
+		}
+	}
+	return dst
+}
@@ -0,0 +1,317 @@
+package spectral
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+)
+
+// freqPSD returns a PSD holding only the frequencies of an n sample
+// transform sampled at rate fs.
+func freqPSD(n int, fs float64) *PSD {
+	return &PSD{Freq: freqs(n/2+1, n, fs), N: n}
+}
+
+func TestButterworthCutoff(t *testing.T) {
+	p := &PSD{Freq: []float64{0, 5, 10, 20, 40, 80}}
+	for _, order := range []int{1, 2, 4} {
+		for _, test := range []struct {
+			name   string
+			filter Filter
+			want   []float64
+		}{
+			{
+				name:   "lowpass",
+				filter: Lowpass{Cutoff: 10, Order: order},
+				want:   []float64{1, math.NaN(), math.Sqrt2 / 2, math.NaN(), math.NaN(), math.NaN()},
+			},
+			{
+				name:   "highpass",
+				filter: Highpass{Cutoff: 10, Order: order},
+				want:   []float64{0, math.NaN(), math.Sqrt2 / 2, math.NaN(), math.NaN(), math.NaN()},
+			},
+			{
+				name:   "bandpass",
+				filter: Bandpass{Low: 5, High: 80, Order: order},
+				want:   []float64{0, math.Sqrt2 / 2, math.NaN(), 1, math.NaN(), math.Sqrt2 / 2},
+			},
+			{
+				name:   "notch",
+				filter: Notch{Low: 5, High: 80, Order: order},
+				want:   []float64{1, math.Sqrt2 / 2, math.NaN(), 0, math.NaN(), math.Sqrt2 / 2},
+			},
+		} {
+			got := test.filter.Gains(make([]float64, len(p.Freq)), p)
+			for i, w := range test.want {
+				if math.IsNaN(w) {
+					continue
+				}
+				if !scalar.EqualWithinAbs(got[i], w, 1e-14) {
+					t.Errorf("unexpected %s gain at %v Hz for order %d: got:%v want:%v", test.name, p.Freq[i], order, got[i], w)
+				}
+			}
+			for i, g := range got {
+				if g < 0 || 1 < g {
+					t.Errorf("%s gain out of range at %v Hz for order %d: %v", test.name, p.Freq[i], order, g)
+				}
+			}
+		}
+
+		// The Butterworth response is |H|² = 1/(1+r²ⁿ).
+		lp := Lowpass{Cutoff: 10, Order: order}.Gains(make([]float64, len(p.Freq)), p)
+		hp := Highpass{Cutoff: 10, Order: order}.Gains(make([]float64, len(p.Freq)), p)
+		for i, f := range p.Freq {
+			want := 1 / (1 + math.Pow(f/10, 2*float64(order)))
+			if !scalar.EqualWithinAbs(lp[i]*lp[i], want, 1e-14) {
+				t.Errorf("unexpected lowpass power gain at %v Hz for order %d: got:%v want:%v", f, order, lp[i]*lp[i], want)
+			}
+			// Complementary power gains for the same cutoff.
+			if !scalar.EqualWithinAbs(lp[i]*lp[i]+hp[i]*hp[i], 1, 1e-14) {
+				t.Errorf("lowpass and highpass power gains do not sum to one at %v Hz for order %d", f, order)
+			}
+		}
+	}
+
+	// Higher orders are sharper.
+	low := Lowpass{Cutoff: 10, Order: 1}.Gains(make([]float64, len(p.Freq)), p)
+	high := Lowpass{Cutoff: 10, Order: 4}.Gains(make([]float64, len(p.Freq)), p)
+	if !(high[1] > low[1] && high[3] < low[3]) {
+		t.Errorf("higher order is not sharper: order 1:%v order 4:%v", low, high)
+	}
+}
+
+func TestIdealGains(t *testing.T) {
+	p := &PSD{Freq: []float64{0, 5, 9, 10, 11, 40, 80, 90}}
+	for _, test := range []struct {
+		name   string
+		filter Filter
+		want   []float64
+	}{
+		{name: "lowpass", filter: Lowpass{Cutoff: 10}, want: []float64{1, 1, 1, 1, 0, 0, 0, 0}},
+		{name: "highpass", filter: Highpass{Cutoff: 10}, want: []float64{0, 0, 0, 1, 1, 1, 1, 1}},
+		{name: "bandpass", filter: Bandpass{Low: 10, High: 80}, want: []float64{0, 0, 0, 1, 1, 1, 1, 0}},
+		{name: "notch", filter: Notch{Low: 10, High: 80}, want: []float64{1, 1, 1, 1, 0, 0, 1, 1}},
+		{
+			name:   "response",
+			filter: Response(func(f float64) float64 { return f / 100 }),
+			want:   []float64{0, 0.05, 0.09, 0.1, 0.11, 0.4, 0.8, 0.9},
+		},
+		{
+			name:   "cascade",
+			filter: Cascade{Lowpass{Cutoff: 40}, Highpass{Cutoff: 9}},
+			want:   []float64{0, 0, 1, 1, 1, 1, 0, 0},
+		},
+		{name: "empty cascade", filter: Cascade{}, want: []float64{1, 1, 1, 1, 1, 1, 1, 1}},
+	} {
+		got := test.filter.Gains(make([]float64, len(p.Freq)), p)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("unexpected %s gains: got:%v want:%v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCascade(t *testing.T) {
+	p := freqPSD(64, 100)
+	filters := []Filter{
+		Lowpass{Cutoff: 30, Order: 2},
+		Highpass{Cutoff: 5, Order: 3},
+		Notch{Low: 10, High: 12, Order: 1},
+	}
+	want := ones(len(p.Freq))
+	for _, f := range filters {
+		g := f.Gains(make([]float64, len(p.Freq)), p)
+		floats.Mul(want, g)
+	}
+	// The destination is overwritten.
+	got := Cascade(filters).Gains(floats.ScaleTo(make([]float64, len(p.Freq)), 7, ones(len(p.Freq))), p)
+	if !floats.EqualApprox(got, want, 1e-15) {
+		t.Errorf("unexpected cascade gains:\ngot: %v\nwant:%v", got, want)
+	}
+}
+
+func TestPSDThreshold(t *testing.T) {
+	const level = 10.0
+	for _, test := range []struct {
+		n     int
+		power []float64
+		want  []float64
+	}{
+		// The Nyquist frequency is the last estimate.
+		{n: 8, power: []float64{6, 6, 11, 4, 6}, want: []float64{1, 0, 1, 0, 1}},
+		{n: 8, power: []float64{4, 10, 11, 4, 5}, want: []float64{0, 0, 1, 0, 0}},
+		// The last estimate is folded.
+		{n: 7, power: []float64{6, 6, 11, 6}, want: []float64{1, 0, 1, 0}},
+	} {
+		p := &PSD{Freq: freqs(len(test.power), test.n, 1), Power: test.power, N: test.n}
+		got := PSDThreshold{Level: level}.Gains(make([]float64, len(test.power)), p)
+		if !floats.Equal(got, test.want) {
+			t.Errorf("unexpected threshold gains for n=%d power=%v: got:%v want:%v", test.n, test.power, got, test.want)
+		}
+	}
+}
+
+func TestWiener(t *testing.T) {
+	const noise = 2.0
+	for _, test := range []struct {
+		n     int
+		power []float64
+		want  []float64
+	}{
+		// The noise density at zero and Nyquist is halved.
+		{n: 8, power: []float64{2, 2, 8, 1, 4}, want: []float64{0.5, 0, 0.75, 0, 0.75}},
+		{n: 8, power: []float64{0, 0, 1, 1, 0.5}, want: []float64{0, 0, 0, 0, 0}},
+		// The last estimate is folded.
+		{n: 7, power: []float64{4, 4, 8, 4}, want: []float64{0.75, 0.5, 0.75, 0.5}},
+	} {
+		p := &PSD{Freq: freqs(len(test.power), test.n, 1), Power: test.power, N: test.n}
+		got := Wiener{Noise: noise}.Gains(make([]float64, len(test.power)), p)
+		if !floats.EqualApprox(got, test.want, 1e-15) {
+			t.Errorf("unexpected Wiener gains for n=%d power=%v: got:%v want:%v", test.n, test.power, got, test.want)
+		}
+	}
+}
+
+// fullApply returns x filtered with the frequency response r applied to
+// the full complex transform of x with gain r(|f|) at each frequency f.
+func fullApply(x []float64, fs float64, r Response) []float64 {
+	n := len(x)
+	fft := fourier.NewCmplxFFT(n)
+	seq := make([]complex128, n)
+	for i, v := range x {
+		seq[i] = complex(v, 0)
+	}
+	coeff := fft.Coefficients(nil, seq)
+	for i := range coeff {
+		f := math.Abs(fft.Freq(i)) * fs
+		coeff[i] *= complex(r(f)/float64(n), 0)
+	}
+	fft.Sequence(seq, coeff)
+	dst := make([]float64, n)
+	for i, v := range seq {
+		dst[i] = real(v)
+	}
+	return dst
+}
+
+// response returns the frequency response of a filter that does not
+// depend on the periodogram.
+func response(f Filter) Response {
+	return func(freq float64) float64 {
+		return f.Gains(make([]float64, 1), &PSD{Freq: []float64{freq}})[0]
+	}
+}
+
+func TestApply(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const fs = 16.0
+	for _, n := range []int{16, 17, 2, 1} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = rnd.NormFloat64()
+		}
+		for _, test := range []struct {
+			name   string
+			filter Filter
+		}{
+			{name: "identity", filter: Response(func(float64) float64 { return 1 })},
+			{name: "ramp", filter: Response(func(f float64) float64 { return 1 - f/fs })},
+			{name: "lowpass", filter: Lowpass{Cutoff: 3, Order: 2}},
+			{name: "highpass", filter: Highpass{Cutoff: 3}},
+			{name: "bandpass", filter: Bandpass{Low: 2, High: 5, Order: 3}},
+			{name: "notch", filter: Notch{Low: 2, High: 5}},
+			{name: "cascade", filter: Cascade{Lowpass{Cutoff: 6, Order: 1}, Notch{Low: 2, High: 3, Order: 2}}},
+		} {
+			got := Apply(nil, x, fs, test.filter)
+			want := fullApply(x, fs, response(test.filter))
+			if !floats.EqualApprox(got, want, 1e-12) {
+				t.Errorf("unexpected %s filtered signal for n=%d:\ngot: %v\nwant:%v", test.name, n, got, want)
+			}
+		}
+	}
+}
+
+func TestApplyZeroNyquist(t *testing.T) {
+	// With an even number of samples, the alternating sequence is
+	// the real Nyquist frequency component.
+	const n = 8
+	x := make([]float64, n)
+	dc := make([]float64, n)
+	nyquist := make([]float64, n)
+	for i := range x {
+		dc[i] = 3 + math.Cos(2*math.Pi*float64(i)/n)
+		nyquist[i] = 2 * math.Cos(math.Pi*float64(i))
+		x[i] = dc[i] + nyquist[i]
+	}
+	if got := Apply(nil, x, n, Lowpass{Cutoff: 2}); !floats.EqualApprox(got, dc, 1e-12) {
+		t.Errorf("unexpected lowpass signal:\ngot: %v\nwant:%v", got, dc)
+	}
+	if got := Apply(nil, x, n, Highpass{Cutoff: 3}); !floats.EqualApprox(got, nyquist, 1e-12) {
+		t.Errorf("unexpected highpass signal:\ngot: %v\nwant:%v", got, nyquist)
+	}
+
+	// With an odd number of samples, the highest frequency
+	// is complex and has a negative frequency partner.
+	const m = 9
+	y := make([]float64, m)
+	high := make([]float64, m)
+	for i := range y {
+		high[i] = math.Sin(2 * math.Pi * 4 * float64(i) / m)
+		y[i] = 3 + high[i]
+	}
+	if got := Apply(nil, y, m, Highpass{Cutoff: 3}); !floats.EqualApprox(got, high, 1e-12) {
+		t.Errorf("unexpected highpass signal for odd length:\ngot: %v\nwant:%v", got, high)
+	}
+
+	// The zero frequency gain scales the mean.
+	half := Response(func(f float64) float64 {
+		if f == 0 {
+			return 0.5
+		}
+		return 1
+	})
+	for _, s := range [][]float64{x, y} {
+		got := Apply(nil, s, 1, half)
+		want := make([]float64, len(s))
+		mean := floats.Sum(s) / float64(len(s))
+		for i, v := range s {
+			want[i] = v - mean/2
+		}
+		if !floats.EqualApprox(got, want, 1e-12) {
+			t.Errorf("unexpected signal with halved mean:\ngot: %v\nwant:%v", got, want)
+		}
+	}
+}
+
+func TestApplyAlias(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{32, 33} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = rnd.NormFloat64()
+		}
+		p := Periodogram(x, 1, nil)
+		for _, f := range []Filter{
+			Lowpass{Cutoff: 0.2, Order: 2},
+			PSDThreshold{Level: p.NoiseLevel()},
+			Wiener{Noise: p.NoiseLevel()},
+		} {
+			want := Apply(nil, x, 1, f)
+			dst := make([]float64, n)
+			if got := Apply(dst, x, 1, f); &got[0] != &dst[0] || !floats.Equal(got, want) {
+				t.Errorf("unexpected result with dst for %T and n=%d", f, n)
+			}
+			y := append([]float64(nil), x...)
+			if got := Apply(y, y, 1, f); !floats.EqualApprox(got, want, 1e-15) {
+				t.Errorf("unexpected result with aliased dst for %T and n=%d:\ngot: %v\nwant:%v", f, n, got, want)
+			}
+		}
+	}
+
+	if !panics(func() { Apply(make([]float64, 3), make([]float64, 4), 1, Lowpass{Cutoff: 1}) }) {
+		t.Error("expected panic for destination length mismatch")
+	}
+}
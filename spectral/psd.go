@@ -0,0 +1,462 @@
+package spectral
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/lapack/lapack64"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ErrFactorize is returned when a matrix factorization fails.
+var ErrFactorize = errors.New("spectral: factorization failed")
+
+// PSD is a one-sided power spectral density estimate. Power is in squared
+// signal units per Hz, and integrating Power over Freq gives the mean
+// squared value of the signal.
+type PSD struct {
+	// Freq holds the frequencies in Hz of each
+	// estimate, from zero to the Nyquist frequency.
+	Freq []float64
+
+	// Power holds the power spectral density
+	// at each frequency.
+	Power []float64
+
+	// DoF is the equivalent degrees of freedom of
+	// the chi-squared distribution of each estimate
+	// at frequencies other than zero and Nyquist.
+	DoF float64
+
+	// N is the length of the transforms used for
+	// the estimate. If N is even, the last element
+	// of Freq is the Nyquist frequency.
+	N int
+}
+
+// folded returns whether the estimate at index i includes the power of
+// the corresponding negative frequency. This is true for all frequencies
+// except zero and Nyquist.
+func (p *PSD) folded(i int) bool {
+	return i != 0 && (p.N%2 == 1 || i != p.N/2)
+}
+
+// ConfInt returns the lower and upper bounds of the confidence intervals
+// of the power estimates at the given confidence level, assuming that
+// DoF·Power/S is chi-squared distributed for a true spectral density S.
+func (p *PSD) ConfInt(level float64) (lower, upper []float64) {
+	if !(0 < level && level < 1) {
+		panic("spectral: confidence level out of range")
+	}
+	dist := distuv.ChiSquared{K: p.DoF}
+	lo := p.DoF / dist.Quantile((1+level)/2)
+	hi := p.DoF / dist.Quantile((1-level)/2)
+	lower = make([]float64, len(p.Power))
+	upper = make([]float64, len(p.Power))
+	for i, v := range p.Power {
+		lower[i] = v * lo
+		upper[i] = v * hi
+	}
+	return lower, upper
+}
+
+// Periodogram returns the periodogram estimate of the power spectral
+// density of x sampled at rate fs after applying the window function in
+// place to a copy of x. If window is nil, the data are not windowed.
+func Periodogram(x []float64, fs float64, window func(seq []float64) []float64) *PSD {
+	seq := make([]float64, len(x))
+	copy(seq, x)
+	w := ones(len(x))
+	if window != nil {
+		window(seq)
+		window(w)
+	}
+	fft := fourier.NewFFT(len(seq))
+	return periodogram(fft.Coefficients(nil, seq), len(seq), fs, sumSq(w))
+}
+
+// periodogram returns the periodogram for the non-negative frequency
+// Fourier coefficients of an n sample signal sampled at rate fs with a
+// window with sum of squares ss.
+func periodogram(coeff []complex128, n int, fs, ss float64) *PSD {
+	power := make([]float64, len(coeff))
+	for i, v := range coeff {
+		a := cmplx.Abs(v)
+		power[i] = a * a
+	}
+	return &PSD{
+		Freq:  freqs(len(coeff), n, fs),
+		Power: oneSided(power, n, fs*ss),
+		DoF:   2,
+		N:     n,
+	}
+}
+
+// Welch returns Welch's estimate of the power spectral density of x
+// sampled at rate fs, averaging the periodograms of the segments defined
+// by s. The degrees of freedom account for the correlation between
+// overlapping segments.
+func Welch(x []float64, fs float64, s STFT) (*PSD, error) {
+	length, hop, pad := s.params()
+	z, err := s.Transform(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, segs := z.Dims()
+	power := make([]float64, rows)
+	for i := range power {
+		for k := 0; k < segs; k++ {
+			a := cmplx.Abs(z.At(i, k))
+			power[i] += a * a
+		}
+		power[i] /= float64(segs)
+	}
+	w := ones(length)
+	if s.Window != nil {
+		s.Window(w)
+	}
+	return &PSD{
+		Freq:  freqs(rows, pad, fs),
+		Power: oneSided(power, pad, fs*sumSq(w)),
+		DoF:   welchDoF(w, hop, segs),
+		N:     pad,
+	}, nil
+}
+
+// welchDoF returns the equivalent degrees of freedom of the average of
+// segs periodograms with window w overlapping by len(w)-hop samples for
+// white noise, following Percival and Walden (1993) eq. 292b.
+func welchDoF(w []float64, hop, segs int) float64 {
+	ss := sumSq(w)
+	var sum float64
+	for m := 1; m < segs && m*hop < len(w); m++ {
+		var rho float64
+		for t := 0; t+m*hop < len(w); t++ {
+			rho += w[t] * w[t+m*hop]
+		}
+		rho /= ss
+		sum += (1 - float64(m)/float64(segs)) * rho * rho
+	}
+	return 2 * float64(segs) / (1 + 2*sum)
+}
+
+// Multitaper returns Thomson's multitaper estimate of the power spectral
+// density of x sampled at rate fs, averaging the eigenspectra of the first
+// k discrete prolate spheroidal sequences with time half-bandwidth product
+// nw. Typically k is at most 2nw-1.
+func Multitaper(x []float64, fs, nw float64, k int) (*PSD, error) {
+	tapers, _, err := DPSS(len(x), nw, k)
+	if err != nil {
+		return nil, err
+	}
+	n := len(x)
+	fft := fourier.NewFFT(n)
+	seq := make([]float64, n)
+	coeff := make([]complex128, n/2+1)
+	power := make([]float64, len(coeff))
+	for j := 0; j < k; j++ {
+		for i, v := range x {
+			seq[i] = v * tapers.At(i, j)
+		}
+		fft.Coefficients(coeff, seq)
+		for i, v := range coeff {
+			a := cmplx.Abs(v)
+			power[i] += a * a
+		}
+	}
+	for i := range power {
+		power[i] /= float64(k)
+	}
+	return &PSD{
+		Freq:  freqs(len(coeff), n, fs),
+		Power: oneSided(power, n, fs),
+		DoF:   2 * float64(k),
+		N:     n,
+	}, nil
+}
+
+// DPSS returns the first k discrete prolate spheroidal (Slepian) sequences
+// of length n with time half-bandwidth product nw as the columns of tapers,
+// and their concentrations, the fraction of the energy of each sequence
+// within the band |f| < nw/n. The sequences have unit norm; symmetric
+// sequences have a positive sum and antisymmetric sequences start with
+// positive values.
+//
+// The sequences are the eigenvectors of the tridiagonal matrix given by
+// Percival and Walden (1993) eq. 378.
+func DPSS(n int, nw float64, k int) (tapers *mat.Dense, concentration []float64, err error) {
+	if n < 1 {
+		return nil, nil, fmt.Errorf("spectral: invalid taper length: %d", n)
+	}
+	if k < 1 || n < k {
+		return nil, nil, fmt.Errorf("spectral: invalid number of tapers: %d", k)
+	}
+	w := nw / float64(n)
+	if w <= 0 || 0.5 <= w {
+		return nil, nil, fmt.Errorf("spectral: invalid half-bandwidth: %v", nw)
+	}
+
+	// Diagonal and off-diagonal elements of the tridiagonal matrix.
+	d := make([]float64, n)
+	e := make([]float64, n-1)
+	for i := range d {
+		c := (float64(n-1) - 2*float64(i)) / 2
+		d[i] = c * c * math.Cos(2*math.Pi*w)
+	}
+	for i := range e {
+		e[i] = float64((i+1)*(n-i-1)) / 2
+	}
+
+	// The sequences are the eigenvectors of the k largest
+	// eigenvalues, which are found by bisection and refined
+	// by inverse iteration.
+	tapers = mat.NewDense(n, k, nil)
+	v := make([]float64, n)
+	for j := 0; j < k; j++ {
+		lambda := tridiagEigenvalue(d, e, n-1-j)
+		err = inverseIterate(v, d, e, lambda)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Inverse iteration loses orthogonality as n grows,
+		// so remove the components along the previous
+		// sequences.
+		for i := 0; i < j; i++ {
+			col := tapers.ColView(i)
+			var dot float64
+			for l, x := range v {
+				dot += x * col.AtVec(l)
+			}
+			for l := range v {
+				v[l] -= dot * col.AtVec(l)
+			}
+		}
+		floats.Scale(1/floats.Norm(v, 2), v)
+
+		var sum float64
+		for i, x := range v {
+			if j%2 == 0 {
+				sum += x
+			} else {
+				sum += (float64(n-1)/2 - float64(i)) * x
+			}
+		}
+		sign := 1.0
+		if sum < 0 {
+			sign = -1
+		}
+		for i, x := range v {
+			tapers.Set(i, j, sign*x)
+		}
+	}
+
+	concentration = make([]float64, k)
+	for j := range concentration {
+		concentration[j] = concentrationOf(tapers.ColView(j), w)
+	}
+	return tapers, concentration, nil
+}
+
+// concentrationOf returns vᵀAv for the n×n matrix with elements
+// A[i,j] = sin(2πw(i-j))/(π(i-j)) and A[i,i] = 2w. Since the elements
+// of A depend only on |i-j|, this is computed from the autocorrelation
+// of v.
+func concentrationOf(v mat.Vector, w float64) float64 {
+	n := v.Len()
+	m := 1
+	for m < 2*n {
+		m <<= 1
+	}
+	seq := make([]float64, m)
+	for i := 0; i < n; i++ {
+		seq[i] = v.AtVec(i)
+	}
+	fft := fourier.NewFFT(m)
+	coeff := fft.Coefficients(nil, seq)
+	for i, c := range coeff {
+		a := cmplx.Abs(c)
+		coeff[i] = complex(a*a, 0)
+	}
+	r := fft.Sequence(seq, coeff)
+
+	sum := 2 * w * r[0]
+	for d := 1; d < n; d++ {
+		sum += 2 * math.Sin(2*math.Pi*w*float64(d)) / (math.Pi * float64(d)) * r[d]
+	}
+	return sum / float64(m)
+}
+
+// NoiseLevel returns an estimate of the level of a white noise spectrum
+// underlying the estimate p. The level is estimated from the median of
+// Power so that it is robust to a small number of spectral peaks.
+func (p *PSD) NoiseLevel() float64 {
+	sorted := make([]float64, len(p.Power))
+	copy(sorted, p.Power)
+	sort.Float64s(sorted)
+	dist := distuv.ChiSquared{K: p.DoF}
+	return sorted[len(sorted)/2] * p.DoF / dist.Quantile(0.5)
+}
+
+// Threshold returns the power that an estimate of a white noise spectrum
+// at the level given by NoiseLevel exceeds at any single frequency with
+// probability alpha. Peaks above the threshold are unlikely to be due to
+// noise; to control the probability of any noise frequency exceeding the
+// threshold, divide alpha by the number of frequencies.
+func (p *PSD) Threshold(alpha float64) float64 {
+	if !(0 < alpha && alpha < 1) {
+		panic("spectral: significance level out of range")
+	}
+	dist := distuv.ChiSquared{K: p.DoF}
+	return p.NoiseLevel() * dist.Quantile(1-alpha) / p.DoF
+}
+
+// oneSided scales the squared magnitudes of the non-negative frequency
+// Fourier coefficients of an n sample transform in place to a one-sided
+// spectral density with normalization norm, doubling all but the zero and
+// Nyquist frequencies.
+func oneSided(power []float64, n int, norm float64) []float64 {
+	p := PSD{N: n}
+	for i := range power {
+		power[i] /= norm
+		if p.folded(i) {
+			power[i] *= 2
+		}
+	}
+	return power
+}
+
+// freqs returns m frequencies spaced at fs/n.
+func freqs(m, n int, fs float64) []float64 {
+	f := make([]float64, m)
+	for i := range f {
+		f[i] = float64(i) * fs / float64(n)
+	}
+	return f
+}
+
+func ones(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = 1
+	}
+	return s
+}
+
+func sumSq(s []float64) float64 {
+	var sum float64
+	for _, v := range s {
+		sum += v * v
+	}
+	return sum
+}
+
+// tridiagEigenvalue returns the eigenvalue with index k, in ascending
+// order, of the symmetric tridiagonal matrix with diagonal d and
+// off-diagonal e, found by bisection using Sturm sequence counts.
+func tridiagEigenvalue(d, e []float64, k int) float64 {
+	// Start from the Gershgorin bounds of the spectrum.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, v := range d {
+		var r float64
+		if i > 0 {
+			r += math.Abs(e[i-1])
+		}
+		if i < len(e) {
+			r += math.Abs(e[i])
+		}
+		lo = math.Min(lo, v-r)
+		hi = math.Max(hi, v+r)
+	}
+	for {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || hi <= mid {
+			return mid
+		}
+		if sturmCount(d, e, mid) > k {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+}
+
+// sturmCount returns the number of eigenvalues less than x of the
+// symmetric tridiagonal matrix with diagonal d and off-diagonal e.
+func sturmCount(d, e []float64, x float64) int {
+	var count int
+	q := d[0] - x
+	for i := 0; ; i++ {
+		if q == 0 {
+			q = -1e-300
+		}
+		if q < 0 {
+			count++
+		}
+		if i == len(e) {
+			return count
+		}
+		q = d[i+1] - x - e[i]*e[i]/q
+	}
+}
+
+// inverseIterate places the unit eigenvector of the symmetric tridiagonal
+// matrix with diagonal d and off-diagonal e for the eigenvalue lambda into
+// v.
+func inverseIterate(v, d, e []float64, lambda float64) error {
+	const iterations = 3
+	n := len(d)
+	if n == 1 {
+		v[0] = 1
+		return nil
+	}
+	dl := make([]float64, n-1)
+	du := make([]float64, n-1)
+	diag := make([]float64, n)
+	start(v)
+	var perturbed bool
+	for it := 0; it < iterations; {
+		copy(dl, e)
+		copy(du, e)
+		for i, x := range d {
+			diag[i] = x - lambda
+		}
+		a := lapack64.Tridiagonal{N: n, DL: dl, D: diag, DU: du}
+		ok := lapack64.Gtsv(blas.NoTrans, a, blas64.General{Rows: n, Cols: 1, Stride: 1, Data: v})
+		if !ok {
+			if perturbed {
+				return ErrFactorize
+			}
+			// λ is exactly an eigenvalue in floating
+			// point, so perturb it and start again.
+			perturbed = true
+			lambda += 4 * math.Abs(lambda) * dlamchE
+			start(v)
+			it = 0
+			continue
+		}
+		floats.Scale(1/floats.Norm(v, 2), v)
+		it++
+	}
+	return nil
+}
+
+// start fills v with the starting vector for inverse iteration. The
+// eigenvectors of the DPSS matrix are alternately symmetric and
+// antisymmetric, so the starting vector is a ramp added to a constant
+// so that it is not orthogonal to either.
+func start(v []float64) {
+	for i := range v {
+		v[i] = 1 + float64(i)/float64(len(v))
+	}
+}
+
+// dlamchE is the machine epsilon.
+const dlamchE = 1.0 / (1 << 53)
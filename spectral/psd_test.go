@@ -0,0 +1,371 @@
+package spectral
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/window"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// whiteNoise returns n samples of zero mean Gaussian noise with standard
+// deviation sigma.
+func whiteNoise(n int, sigma float64, rnd *rand.Rand) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = sigma * rnd.NormFloat64()
+	}
+	return x
+}
+
+// integral returns the integral of the estimate over frequency.
+func integral(p *PSD) float64 {
+	return floats.Sum(p.Power) * (p.Freq[1] - p.Freq[0])
+}
+
+func TestDPSS(t *testing.T) {
+	const (
+		n  = 512
+		nw = 4
+		k  = 7
+	)
+	tapers, conc, err := DPSS(n, nw, k)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Concentrations for NW=4 given by Percival and Walden (1993) are
+	// 0.99999999971 for the first sequence and 0.9367 for the seventh.
+	if !scalar.EqualWithinAbs(conc[0], 0.99999999971, 5e-12) {
+		t.Errorf("unexpected concentration of sequence 0: got:%.11f want:0.99999999971", conc[0])
+	}
+	if !scalar.EqualWithinAbs(conc[6], 0.9367, 5e-5) {
+		t.Errorf("unexpected concentration of sequence 6: got:%.4f want:0.9367", conc[6])
+	}
+
+	// The sequences are eigenvectors of the n×n concentration matrix
+	// with the concentrations as eigenvalues.
+	w := nw / float64(n)
+	a := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		a.SetSym(i, i, 2*w)
+		for j := i + 1; j < n; j++ {
+			d := float64(i - j)
+			a.SetSym(i, j, math.Sin(2*math.Pi*w*d)/(math.Pi*d))
+		}
+	}
+	for j := 0; j < k; j++ {
+		if j > 0 && conc[j] >= conc[j-1] {
+			t.Errorf("concentrations not decreasing at %d: %v", j, conc)
+		}
+		v := tapers.ColView(j)
+		var av mat.VecDense
+		av.MulVec(a, v)
+		if got := mat.Dot(v, &av); !scalar.EqualWithinAbs(got, conc[j], 1e-12) {
+			t.Errorf("unexpected concentration of sequence %d: got:%v want:%v", j, conc[j], got)
+		}
+		av.AddScaledVec(&av, -conc[j], v)
+		if r := mat.Norm(&av, 2); r > 1e-12 {
+			t.Errorf("sequence %d is not an eigenvector: residual=%g", j, r)
+		}
+
+		// Even sequences are symmetric with a positive sum and odd
+		// sequences are antisymmetric and start with positive values.
+		sign := 1.0
+		if j%2 == 1 {
+			sign = -1
+		}
+		var sum float64
+		for i := 0; i < n; i++ {
+			sum += v.AtVec(i)
+			if d := v.AtVec(i) - sign*v.AtVec(n-1-i); math.Abs(d) > 1e-12 {
+				t.Errorf("unexpected symmetry of sequence %d at %d: %g", j, i, d)
+				break
+			}
+		}
+		if j%2 == 0 && sum <= 0 {
+			t.Errorf("unexpected sign of sequence %d: sum=%v", j, sum)
+		}
+		if j%2 == 1 && v.AtVec(1) <= 0 {
+			t.Errorf("unexpected sign of sequence %d: v[1]=%v", j, v.AtVec(1))
+		}
+	}
+}
+
+func TestDPSSOrthonormal(t *testing.T) {
+	for _, test := range []struct {
+		n  int
+		nw float64
+		k  int
+	}{
+		{n: 64, nw: 4, k: 7},
+		{n: 512, nw: 4, k: 7},
+		{n: 1024, nw: 4, k: 8},
+		{n: 4096, nw: 2.5, k: 4},
+		{n: 101, nw: 3, k: 6},
+		{n: 2, nw: 0.5, k: 2},
+		{n: 1, nw: 0.25, k: 1},
+	} {
+		tapers, _, err := DPSS(test.n, test.nw, test.k)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var gram mat.Dense
+		gram.Mul(tapers.T(), tapers)
+		for i := 0; i < test.k; i++ {
+			for j := 0; j < test.k; j++ {
+				want := 0.0
+				if i == j {
+					want = 1
+				}
+				if d := math.Abs(gram.At(i, j) - want); d > 1e-13 {
+					t.Errorf("unexpected inner product of sequences %d and %d for n=%d nw=%v: got:%g want:%v",
+						i, j, test.n, test.nw, gram.At(i, j), want)
+				}
+			}
+		}
+	}
+}
+
+func TestDPSSErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		n    int
+		nw   float64
+		k    int
+	}{
+		{name: "zero length", n: 0, nw: 4, k: 1},
+		{name: "zero tapers", n: 64, nw: 4, k: 0},
+		{name: "too many tapers", n: 4, nw: 1, k: 5},
+		{name: "zero bandwidth", n: 64, nw: 0, k: 1},
+		{name: "bandwidth above Nyquist", n: 64, nw: 32, k: 1},
+	} {
+		_, _, err := DPSS(test.n, test.nw, test.k)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
+
+func TestPSDVariance(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const (
+		fs    = 200.0
+		sigma = 1.5
+	)
+	for _, n := range []int{4096, 4095} {
+		x := whiteNoise(n, sigma, rnd)
+		mean := stat.Mean(x, nil)
+		floats.AddConst(-mean, x)
+		variance := stat.PopVariance(x, nil)
+
+		// The unwindowed periodogram satisfies Parseval's theorem.
+		p := Periodogram(x, fs, nil)
+		if len(p.Power) != n/2+1 || p.N != n || p.DoF != 2 {
+			t.Errorf("unexpected periodogram shape for n=%d: len=%d N=%d DoF=%v", n, len(p.Power), p.N, p.DoF)
+		}
+		if got := integral(p); !scalar.EqualWithinRel(got, variance, 1e-12) {
+			t.Errorf("unexpected periodogram integral for n=%d: got:%v want:%v", n, got, variance)
+		}
+		if got, want := p.Freq[len(p.Freq)-1], float64(n/2)*fs/float64(n); got != want {
+			t.Errorf("unexpected maximum frequency for n=%d: got:%v want:%v", n, got, want)
+		}
+
+		// The windowed estimates are unbiased for white noise,
+		// so they integrate to the variance within their
+		// sampling error.
+		hann := Periodogram(x, fs, window.Hann)
+		welch, err := Welch(x, fs, STFT{Length: 256, Window: window.Hann})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mt, err := Multitaper(x, fs, 4, 7)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, test := range []struct {
+			name string
+			psd  *PSD
+		}{
+			{name: "Hann periodogram", psd: hann},
+			{name: "Welch", psd: welch},
+			{name: "multitaper", psd: mt},
+		} {
+			if got := integral(test.psd); !scalar.EqualWithinRel(got, variance, 0.05) {
+				t.Errorf("unexpected %s integral for n=%d: got:%v want:%v", test.name, n, got, variance)
+			}
+			// The level of a one-sided white noise spectrum
+			// is 2σ²/fs.
+			if got, want := test.psd.NoiseLevel(), 2*sigma*sigma/fs; !scalar.EqualWithinRel(got, want, 0.15) {
+				t.Errorf("unexpected %s noise level for n=%d: got:%v want:%v", test.name, n, got, want)
+			}
+		}
+		if mt.DoF != 14 {
+			t.Errorf("unexpected multitaper degrees of freedom: got:%v want:14", mt.DoF)
+		}
+	}
+}
+
+func TestWelch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := whiteNoise(1000, 1, rnd)
+
+	// Without a window or overlap, Welch's estimate is the mean of the
+	// periodograms of the segments.
+	s := STFT{Length: 100, Hop: 100}
+	got, err := Welch(x, 10, s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := make([]float64, 51)
+	for k := 0; k < 10; k++ {
+		p := Periodogram(x[k*100:(k+1)*100], 10, nil)
+		floats.AddScaled(want, 0.1, p.Power)
+	}
+	if !floats.EqualApprox(got.Power, want, 1e-12) {
+		t.Errorf("unexpected Welch estimate:\ngot: %v\nwant:%v", got.Power, want)
+	}
+	if got.DoF != 20 {
+		t.Errorf("unexpected degrees of freedom: got:%v want:20", got.DoF)
+	}
+	if got.N != 100 || len(got.Freq) != 51 || got.Freq[50] != 5 {
+		t.Errorf("unexpected frequencies: N=%d len=%d max=%v", got.N, len(got.Freq), got.Freq[len(got.Freq)-1])
+	}
+
+	_, err = Welch(x[:50], 10, s)
+	if err == nil {
+		t.Error("expected error for short signal")
+	}
+}
+
+func TestWelchDoF(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		w    []float64
+		hop  int
+		segs int
+		want float64
+	}{
+		{name: "single segment", w: ones(8), hop: 4, segs: 1, want: 2},
+		{name: "no overlap", w: ones(8), hop: 8, segs: 5, want: 10},
+		{name: "gap", w: ones(8), hop: 10, segs: 5, want: 10},
+		// ρ(1) = 1/2, so the sum is (1-1/2)·1/4.
+		{name: "half overlap", w: ones(2), hop: 1, segs: 2, want: 4 / 1.25},
+		// ρ(1) = 2/3 and ρ(2) = 1/3.
+		{name: "two thirds overlap", w: ones(3), hop: 1, segs: 3, want: 6 / (1 + 2*(4.0/9*2/3+1.0/9/3))},
+	} {
+		got := welchDoF(test.w, test.hop, test.segs)
+		if !scalar.EqualWithinAbsOrRel(got, test.want, 1e-14, 1e-14) {
+			t.Errorf("unexpected degrees of freedom for %s: got:%v want:%v", test.name, got, test.want)
+		}
+	}
+
+	// The degrees of freedom match the sampling variance of the
+	// estimates of overlapping Hann windowed segments, for which
+	// an estimate P with ν degrees of freedom has var(P) = 2E[P]²/ν.
+	rnd := rand.New(rand.NewSource(1))
+	s := STFT{Length: 64, Window: window.Hann}
+	const reps = 400
+	var est []float64
+	var dof float64
+	for r := 0; r < reps; r++ {
+		p, err := Welch(whiteNoise(512, 1, rnd), 1, s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dof = p.DoF
+		for i := 1; i < len(p.Power)-1; i++ {
+			est = append(est, p.Power[i])
+		}
+	}
+	mean, variance := stat.MeanVariance(est, nil)
+	if got := 2 * mean * mean / variance; !scalar.EqualWithinRel(got, dof, 0.1) {
+		t.Errorf("unexpected empirical degrees of freedom: got:%v want:%v", got, dof)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, alpha := range []float64{0.1, 0.01} {
+		var exceed, total int
+		for r := 0; r < 20; r++ {
+			p := Periodogram(whiteNoise(2048, 1, rnd), 1, nil)
+			thresh := p.Threshold(alpha)
+			for i, v := range p.Power {
+				if !p.folded(i) {
+					continue
+				}
+				total++
+				if v > thresh {
+					exceed++
+				}
+			}
+		}
+		if got := float64(exceed) / float64(total); !scalar.EqualWithinRel(got, alpha, 0.2) {
+			t.Errorf("unexpected rate of exceeding threshold: got:%v want:%v", got, alpha)
+		}
+	}
+
+	// A peak in the spectrum is above the threshold.
+	x := whiteNoise(2048, 1, rnd)
+	for i := range x {
+		x[i] += math.Sin(2 * math.Pi * 0.25 * float64(i))
+	}
+	p := Periodogram(x, 1, nil)
+	if p.Power[512] <= p.Threshold(0.01/float64(len(p.Power))) {
+		t.Error("sinusoid not above threshold")
+	}
+
+	for _, alpha := range []float64{0, 1, -1, math.NaN()} {
+		if !panics(func() { p.Threshold(alpha) }) {
+			t.Errorf("expected panic for significance level %v", alpha)
+		}
+	}
+}
+
+func TestConfInt(t *testing.T) {
+	p := &PSD{Power: []float64{1, 2}, DoF: 2}
+	lower, upper := p.ConfInt(0.95)
+	// The 0.025 and 0.975 quantiles of χ²₂ are -2ln(0.975) and -2ln(0.025).
+	lo, hi := 2/(-2*math.Log(0.025)), 2/(-2*math.Log(0.975))
+	for i, v := range p.Power {
+		if !scalar.EqualWithinRel(lower[i], v*lo, 1e-10) || !scalar.EqualWithinRel(upper[i], v*hi, 1e-10) {
+			t.Errorf("unexpected interval %d: got:[%v, %v] want:[%v, %v]", i, lower[i], upper[i], v*lo, v*hi)
+		}
+	}
+
+	// The intervals cover the true level at the requested rate.
+	rnd := rand.New(rand.NewSource(1))
+	const level = 0.9
+	s := STFT{Length: 128, Window: window.Hann}
+	var covered, total int
+	for r := 0; r < 20; r++ {
+		p, err := Welch(whiteNoise(2048, 1, rnd), 1, s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		lower, upper := p.ConfInt(level)
+		for i := range p.Power {
+			if !p.folded(i) {
+				continue
+			}
+			total++
+			if lower[i] <= 2 && 2 <= upper[i] {
+				covered++
+			}
+		}
+	}
+	if got := float64(covered) / float64(total); math.Abs(got-level) > 0.03 {
+		t.Errorf("unexpected coverage: got:%v want:%v", got, level)
+	}
+
+	for _, level := range []float64{0, 1, 1.5, math.NaN()} {
+		if !panics(func() { p.ConfInt(level) }) {
+			t.Errorf("expected panic for confidence level %v", level)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package spectral
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/mat"
+)
+
+// STFT is a short-time Fourier transform. The signal is split into
+// segments which are windowed, zero padded and transformed.
+type STFT struct {
+	// Length is the number of samples in each segment.
+	Length int
+
+	// Hop is the number of samples between the starts
+	// of successive segments. If Hop is zero, Length/2
+	// is used.
+	Hop int
+
+	// Pad is the length of each segment after zero
+	// padding. If Pad is zero, no padding is done,
+	// otherwise Pad must not be less than Length.
+	Pad int
+
+	// Window is applied in place to each segment and
+	// returns it. Window is typically one of the window
+	// functions in gonum.org/v1/gonum/dsp/window or
+	// returned by Gabor. If Window is nil, segments
+	// are not windowed.
+	Window func(seq []float64) []float64
+
+	// Center specifies that the signal is extended by
+	// Length/2 zeros at each end so that segment k is
+	// centered on sample k*Hop.
+	Center bool
+}
+
+// Gabor returns a Gaussian window, exp(-a t²), for a signal with
+// sample rate fs, where t is the time in seconds from the center of
+// the segment. A short-time Fourier transform with a Gabor window and
+// a segment length covering the effective width of the Gaussian is a
+// Gabor transform.
+func Gabor(a, fs float64) func(seq []float64) []float64 {
+	return func(seq []float64) []float64 {
+		mid := float64(len(seq)-1) / 2
+		for i := range seq {
+			t := (float64(i) - mid) / fs
+			seq[i] *= math.Exp(-a * t * t)
+		}
+		return seq
+	}
+}
+
+func (s STFT) params() (length, hop, pad int) {
+	length, hop, pad = s.Length, s.Hop, s.Pad
+	if length <= 0 {
+		panic("spectral: invalid segment length")
+	}
+	if hop == 0 {
+		hop = length / 2
+		if hop == 0 {
+			hop = 1
+		}
+	}
+	if hop < 0 {
+		panic("spectral: invalid hop")
+	}
+	if pad == 0 {
+		pad = length
+	}
+	if pad < length {
+		panic("spectral: padded length less than segment length")
+	}
+	return length, hop, pad
+}
+
+// Segments returns the number of segments in a signal of n samples.
+func (s STFT) Segments(n int) int {
+	length, hop, _ := s.params()
+	if s.Center {
+		n += 2 * (length / 2)
+	}
+	if n < length {
+		return 0
+	}
+	return (n-length)/hop + 1
+}
+
+// Transform returns the short-time Fourier transform of x. The returned
+// matrix has a row for each of the Pad/2+1 non-negative frequencies and a
+// column for each segment. The transform is unnormalized.
+func (s STFT) Transform(x []float64) (*mat.CDense, error) {
+	length, hop, pad := s.params()
+	segs := s.Segments(len(x))
+	if segs == 0 {
+		return nil, fmt.Errorf("spectral: signal too short for segment length: %d < %d", len(x), length)
+	}
+	var offset int
+	if s.Center {
+		offset = length / 2
+	}
+
+	fft := fourier.NewFFT(pad)
+	seq := make([]float64, pad)
+	coeff := make([]complex128, pad/2+1)
+	dst := mat.NewCDense(len(coeff), segs, nil)
+	for k := 0; k < segs; k++ {
+		for i := range seq {
+			seq[i] = 0
+		}
+		start := k*hop - offset
+		for i := 0; i < length; i++ {
+			if j := start + i; 0 <= j && j < len(x) {
+				seq[i] = x[j]
+			}
+		}
+		if s.Window != nil {
+			s.Window(seq[:length])
+		}
+		fft.Coefficients(coeff, seq)
+		for i, v := range coeff {
+			dst.Set(i, k, v)
+		}
+	}
+	return dst, nil
+}
+
+// Spectrogram returns the squared magnitude of the short-time Fourier
+// transform of x. The layout of the returned matrix is the same as for
+// Transform.
+func (s STFT) Spectrogram(x []float64) (*mat.Dense, error) {
+	z, err := s.Transform(x)
+	if err != nil {
+		return nil, err
+	}
+	r, c := z.Dims()
+	dst := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := cmplx.Abs(z.At(i, j))
+			dst.Set(i, j, v*v)
+		}
+	}
+	return dst, nil
+}
+
+// Times returns the times in seconds of the centers of the segments of a
+// signal of n samples with sample rate fs, placing the result in dst and
+// returning it. If dst is nil, a new slice is allocated.
+func (s STFT) Times(dst []float64, n int, fs float64) []float64 {
+	length, hop, _ := s.params()
+	segs := s.Segments(n)
+	if dst == nil {
+		dst = make([]float64, segs)
+	} else if len(dst) != segs {
+		panic("spectral: destination length mismatch")
+	}
+	center := float64(length-1) / 2
+	if s.Center {
+		center -= float64(length / 2)
+	}
+	for k := range dst {
+		dst[k] = (float64(k*hop) + center) / fs
+	}
+	return dst
+}
+
+// Freqs returns the frequencies in Hz of the rows of the transform for a
+// signal with sample rate fs, placing the result in dst and returning it.
+// If dst is nil, a new slice is allocated.
+func (s STFT) Freqs(dst []float64, fs float64) []float64 {
+	_, _, pad := s.params()
+	if dst == nil {
+		dst = make([]float64, pad/2+1)
+	} else if len(dst) != pad/2+1 {
+		panic("spectral: destination length mismatch")
+	}
+	for i := range dst {
+		dst[i] = float64(i) * fs / float64(pad)
+	}
+	return dst
+}
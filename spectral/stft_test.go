@@ -0,0 +1,223 @@
+package spectral
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/dsp/window"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+)
+
+func TestSTFTSegments(t *testing.T) {
+	for _, test := range []struct {
+		stft STFT
+		n    int
+		want int
+	}{
+		{stft: STFT{Length: 8}, n: 8, want: 1},
+		{stft: STFT{Length: 8}, n: 7, want: 0},
+		{stft: STFT{Length: 8}, n: 16, want: 3},
+		{stft: STFT{Length: 8}, n: 19, want: 3},
+		{stft: STFT{Length: 8}, n: 20, want: 4},
+		{stft: STFT{Length: 8, Hop: 8}, n: 20, want: 2},
+		{stft: STFT{Length: 8, Hop: 3}, n: 20, want: 5},
+		{stft: STFT{Length: 1}, n: 5, want: 5},
+		{stft: STFT{Length: 8, Center: true}, n: 16, want: 5},
+		{stft: STFT{Length: 8, Center: true}, n: 1, want: 1},
+		{stft: STFT{Length: 8, Center: true}, n: 0, want: 1},
+		{stft: STFT{Length: 9, Hop: 4, Center: true}, n: 16, want: 4},
+		{stft: STFT{Length: 9, Hop: 4, Center: true}, n: 0, want: 0},
+	} {
+		if got := test.stft.Segments(test.n); got != test.want {
+			t.Errorf("unexpected number of segments for %+v with n=%d: got:%d want:%d", test.stft, test.n, got, test.want)
+		}
+	}
+}
+
+func TestSTFTAxes(t *testing.T) {
+	const fs = 100.0
+	for _, s := range []STFT{
+		{Length: 8},
+		{Length: 8, Hop: 3, Pad: 32},
+		{Length: 9, Hop: 4, Center: true},
+		{Length: 8, Hop: 4, Center: true},
+	} {
+		_, hop, pad := s.params()
+		freqs := s.Freqs(nil, fs)
+		if len(freqs) != pad/2+1 {
+			t.Errorf("unexpected number of frequencies for %+v: got:%d want:%d", s, len(freqs), pad/2+1)
+		}
+		if freqs[0] != 0 || freqs[len(freqs)-1] != fs*float64(pad/2)/float64(pad) {
+			t.Errorf("unexpected frequency range for %+v: [%v, %v]", s, freqs[0], freqs[len(freqs)-1])
+		}
+
+		const n = 50
+		times := s.Times(nil, n, fs)
+		if len(times) != s.Segments(n) {
+			t.Errorf("unexpected number of times for %+v: got:%d want:%d", s, len(times), s.Segments(n))
+		}
+		var offset int
+		if s.Center {
+			offset = s.Length / 2
+		}
+		for k, got := range times {
+			// The time is that of the middle sample of the segment.
+			start := k*hop - offset
+			want := (float64(start) + float64(s.Length-1)/2) / fs
+			if !scalar.EqualWithinAbs(got, want, 1e-12) {
+				t.Errorf("unexpected time of segment %d for %+v: got:%v want:%v", k, s, got, want)
+			}
+			// Odd length centered segments are centered on sample k*Hop.
+			if s.Center && s.Length%2 == 1 && !scalar.EqualWithinAbs(got, float64(k*hop)/fs, 1e-12) {
+				t.Errorf("centered segment %d not at k*Hop for %+v: got:%v want:%v", k, s, got, float64(k*hop)/fs)
+			}
+		}
+
+		// An impulse appears only in the segments covering it, and
+		// the segment with the nearest time contains it.
+		const at = 23
+		x := make([]float64, n)
+		x[at] = 1
+		z, err := s.Transform(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nearest := 0
+		for k := range times {
+			if math.Abs(times[k]-at/fs) < math.Abs(times[nearest]-at/fs) {
+				nearest = k
+			}
+			start := k*hop - offset
+			covered := start <= at && at < start+s.Length
+			if got := cmplx.Abs(z.At(0, k)); (got != 0) != covered {
+				t.Errorf("unexpected impulse response in segment %d for %+v: got:%v covered:%t", k, s, got, covered)
+			}
+		}
+		if cmplx.Abs(z.At(0, nearest)) != 1 {
+			t.Errorf("impulse not in nearest segment %d for %+v", nearest, s)
+		}
+	}
+}
+
+func TestSTFTTransform(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float64, 40)
+	for i := range x {
+		x[i] = rnd.NormFloat64()
+	}
+	s := STFT{Length: 10, Hop: 7, Pad: 16, Window: window.Hann}
+	z, err := s.Transform(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r, c := z.Dims(); r != 9 || c != s.Segments(len(x)) {
+		t.Errorf("unexpected dimensions: got:%d×%d want:9×%d", r, c, s.Segments(len(x)))
+	}
+
+	// Each column is the transform of the windowed, zero padded segment.
+	fft := fourier.NewFFT(16)
+	for k := 0; k < s.Segments(len(x)); k++ {
+		seq := make([]float64, 16)
+		copy(seq, x[k*7:k*7+10])
+		window.Hann(seq[:10])
+		want := fft.Coefficients(nil, seq)
+		for i, w := range want {
+			if cmplx.Abs(z.At(i, k)-w) > 1e-12 {
+				t.Errorf("unexpected coefficient %d of segment %d: got:%v want:%v", i, k, z.At(i, k), w)
+			}
+		}
+	}
+
+	_, err = s.Transform(x[:9])
+	if err == nil {
+		t.Error("expected error for short signal")
+	}
+}
+
+func TestSpectrogramTone(t *testing.T) {
+	const (
+		fs = 1000.0
+		n  = 2000
+	)
+	for _, test := range []struct {
+		f0   float64
+		stft STFT
+	}{
+		// On a bin of the unpadded transform.
+		{f0: 125, stft: STFT{Length: 64, Window: window.Hann}},
+		// Between bins, resolved by padding.
+		{f0: 130, stft: STFT{Length: 64, Pad: 256, Window: window.Hann, Center: true}},
+		{f0: 130, stft: STFT{Length: 100, Hop: 10, Window: Gabor(2000, fs)}},
+	} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = math.Sin(2 * math.Pi * test.f0 * float64(i) / fs)
+		}
+		spec, err := test.stft.Spectrogram(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		freqs := test.stft.Freqs(nil, fs)
+		df := freqs[1]
+		_, c := spec.Dims()
+		col := make([]float64, len(freqs))
+		// Skip the zero padded ends of centered transforms.
+		for k := 1; k < c-1; k++ {
+			for i := range col {
+				col[i] = spec.At(i, k)
+			}
+			if got := freqs[floats.MaxIdx(col)]; math.Abs(got-test.f0) > df/2 {
+				t.Errorf("unexpected peak frequency in segment %d for %+v: got:%v want:%v±%v", k, test.stft, got, test.f0, df/2)
+			}
+		}
+	}
+}
+
+func TestGabor(t *testing.T) {
+	const (
+		a  = 50.0
+		fs = 10.0
+	)
+	seq := make([]float64, 11)
+	for i := range seq {
+		seq[i] = 1
+	}
+	Gabor(a, fs)(seq)
+	for i, v := range seq {
+		tt := float64(i-5) / fs
+		if want := math.Exp(-a * tt * tt); !scalar.EqualWithinAbsOrRel(v, want, 1e-15, 1e-15) {
+			t.Errorf("unexpected window value %d: got:%v want:%v", i, v, want)
+		}
+		if v != seq[len(seq)-1-i] {
+			t.Errorf("window is not symmetric at %d", i)
+		}
+	}
+}
+
+func TestSTFTPanics(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fn   func()
+	}{
+		{name: "zero length", fn: func() { STFT{}.Segments(10) }},
+		{name: "negative length", fn: func() { STFT{Length: -1}.Segments(10) }},
+		{name: "negative hop", fn: func() { STFT{Length: 4, Hop: -1}.Segments(10) }},
+		{name: "short pad", fn: func() { STFT{Length: 8, Pad: 4}.Segments(10) }},
+		{name: "short pad transform", fn: func() { STFT{Length: 8, Pad: 7}.Transform(make([]float64, 10)) }},
+		{name: "times length", fn: func() { STFT{Length: 4}.Times(make([]float64, 2), 10, 1) }},
+		{name: "freqs length", fn: func() { STFT{Length: 4}.Freqs(make([]float64, 2), 1) }},
+	} {
+		if !panics(test.fn) {
+			t.Errorf("expected panic for %s", test.name)
+		}
+	}
+
+	// A small segment length gives a hop of at least one.
+	if got := (STFT{Length: 1}).Segments(3); got != 3 {
+		t.Errorf("unexpected number of segments for unit length: got:%d want:3", got)
+	}
+}